@@ -0,0 +1,244 @@
+package codejen
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// The converters in this file lift a jenny of one arity into another, so that
+// it may be used where only a particular jenny interface is accepted. Like the
+// Adapt* funcs, converted jennies are transparent: they report the name of the
+// jenny they wrap, and do not modify [File.From].
+
+type o2oAsO2m[I Input] struct {
+	j OneToOneContext[I]
+}
+
+func (c *o2oAsO2m[I]) JennyName() string {
+	return c.j.JennyName()
+}
+
+func (c *o2oAsO2m[I]) Generate(t I) (Files, error) {
+	return c.GenerateContext(context.Background(), t)
+}
+
+func (c *o2oAsO2m[I]) GenerateContext(ctx context.Context, t I) (Files, error) {
+	f, err := c.j.GenerateContext(ctx, t)
+	if err != nil || f == nil || !f.Exists() {
+		return nil, err
+	}
+	return Files{*f}, nil
+}
+
+// OneToOneAsOneToMany converts a OneToOne jenny into a OneToMany jenny, which
+// returns the single output File of the OneToOne jenny, if any.
+//
+// If j is also a [OneToOneContext], the returned jenny passes its context
+// through to j.
+func OneToOneAsOneToMany[I Input](j OneToOne[I]) OneToMany[I] {
+	jc, ok := j.(OneToOneContext[I])
+	if !ok {
+		jc = ContextOneToOne(j)
+	}
+	return &o2oAsO2m[I]{
+		j: jc,
+	}
+}
+
+type o2oAsM2m[I Input] struct {
+	j OneToOneContext[I]
+}
+
+func (c *o2oAsM2m[I]) JennyName() string {
+	return c.j.JennyName()
+}
+
+func (c *o2oAsM2m[I]) Generate(ts ...I) (Files, error) {
+	return c.GenerateContext(context.Background(), ts...)
+}
+
+func (c *o2oAsM2m[I]) GenerateContext(ctx context.Context, ts ...I) (Files, error) {
+	var fl Files
+	var result *multierror.Error
+	for i, t := range ts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, err := c.j.GenerateContext(ctx, t)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("input %d: %w", i, err))
+		} else if f != nil && f.Exists() {
+			fl = append(fl, *f)
+		}
+	}
+	if result.ErrorOrNil() != nil {
+		return nil, result
+	}
+	return fl, nil
+}
+
+// OneToOneAsManyToMany converts a OneToOne jenny into a ManyToMany jenny, which
+// calls the OneToOne jenny once for each input, in order, and returns all of
+// the output Files. Errors from all inputs are aggregated.
+//
+// If j is also a [OneToOneContext], the returned jenny passes its context
+// through to j.
+func OneToOneAsManyToMany[I Input](j OneToOne[I]) ManyToMany[I] {
+	jc, ok := j.(OneToOneContext[I])
+	if !ok {
+		jc = ContextOneToOne(j)
+	}
+	return &o2oAsM2m[I]{
+		j: jc,
+	}
+}
+
+type o2mAsM2m[I Input] struct {
+	j OneToManyContext[I]
+}
+
+func (c *o2mAsM2m[I]) JennyName() string {
+	return c.j.JennyName()
+}
+
+func (c *o2mAsM2m[I]) Generate(ts ...I) (Files, error) {
+	return c.GenerateContext(context.Background(), ts...)
+}
+
+func (c *o2mAsM2m[I]) GenerateContext(ctx context.Context, ts ...I) (Files, error) {
+	var fl Files
+	var result *multierror.Error
+	for i, t := range ts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ofl, err := c.j.GenerateContext(ctx, t)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("input %d: %w", i, err))
+		} else {
+			fl = append(fl, ofl...)
+		}
+	}
+	if result.ErrorOrNil() != nil {
+		return nil, result
+	}
+	return fl, nil
+}
+
+// OneToManyAsManyToMany converts a OneToMany jenny into a ManyToMany jenny,
+// which calls the OneToMany jenny once for each input, in order, and returns
+// the concatenation of all of the output Files. Errors from all inputs are
+// aggregated.
+//
+// If j is also a [OneToManyContext], the returned jenny passes its context
+// through to j.
+func OneToManyAsManyToMany[I Input](j OneToMany[I]) ManyToMany[I] {
+	jc, ok := j.(OneToManyContext[I])
+	if !ok {
+		jc = ContextOneToMany(j)
+	}
+	return &o2mAsM2m[I]{
+		j: jc,
+	}
+}
+
+type m2oAsM2m[I Input] struct {
+	j ManyToOneContext[I]
+}
+
+func (c *m2oAsM2m[I]) JennyName() string {
+	return c.j.JennyName()
+}
+
+func (c *m2oAsM2m[I]) Generate(ts ...I) (Files, error) {
+	return c.GenerateContext(context.Background(), ts...)
+}
+
+func (c *m2oAsM2m[I]) GenerateContext(ctx context.Context, ts ...I) (Files, error) {
+	f, err := c.j.GenerateContext(ctx, ts...)
+	if err != nil || f == nil || !f.Exists() {
+		return nil, err
+	}
+	return Files{*f}, nil
+}
+
+// ManyToOneAsManyToMany converts a ManyToOne jenny into a ManyToMany jenny,
+// which returns the single output File of the ManyToOne jenny, if any.
+//
+// If j is also a [ManyToOneContext], the returned jenny passes its context
+// through to j.
+func ManyToOneAsManyToMany[I Input](j ManyToOne[I]) ManyToMany[I] {
+	jc, ok := j.(ManyToOneContext[I])
+	if !ok {
+		jc = ContextManyToOne(j)
+	}
+	return &m2oAsM2m[I]{
+		j: jc,
+	}
+}
+
+type m2oGroup[I Input] struct {
+	key func(I) string
+	j   ManyToOneContext[I]
+}
+
+func (c *m2oGroup[I]) JennyName() string {
+	return c.j.JennyName()
+}
+
+func (c *m2oGroup[I]) Generate(ts ...I) (Files, error) {
+	return c.GenerateContext(context.Background(), ts...)
+}
+
+func (c *m2oGroup[I]) GenerateContext(ctx context.Context, ts ...I) (Files, error) {
+	var keys []string
+	groups := make(map[string][]I)
+	for _, t := range ts {
+		k := c.key(t)
+		if _, has := groups[k]; !has {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], t)
+	}
+
+	var fl Files
+	var result *multierror.Error
+	for _, k := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, err := c.j.GenerateContext(ctx, groups[k]...)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("group %q: %w", k, err))
+		} else if f != nil && f.Exists() {
+			fl = append(fl, *f)
+		}
+	}
+	if result.ErrorOrNil() != nil {
+		return nil, result
+	}
+	return fl, nil
+}
+
+// GroupManyToOne converts a ManyToOne jenny into a ManyToMany jenny by
+// partitioning inputs into groups with the provided key func. The ManyToOne
+// jenny is called once per group, producing one File per group.
+//
+// Groups are ordered by the first occurrence of their key in the inputs, and
+// inputs within a group retain their relative order. Errors from all groups are
+// aggregated.
+//
+// If j is also a [ManyToOneContext], the returned jenny passes its context
+// through to j.
+func GroupManyToOne[I Input](j ManyToOne[I], key func(I) string) ManyToMany[I] {
+	jc, ok := j.(ManyToOneContext[I])
+	if !ok {
+		jc = ContextManyToOne(j)
+	}
+	return &m2oGroup[I]{
+		key: key,
+		j:   jc,
+	}
+}
@@ -0,0 +1,104 @@
+package codejen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// ConflictResolver combines two Files having the same RelativePath into one.
+// existing is the File that was added first, and incoming the File added
+// after it. The returned File must have the same RelativePath as both.
+//
+// If the Files cannot be combined, a ConflictResolver returns an error, which
+// is reported as a path conflict. A nil ConflictResolver treats every
+// conflict as an error, and is the default wherever a ConflictResolver is
+// accepted.
+type ConflictResolver func(existing, incoming File) (File, error)
+
+var _ ConflictResolver = FirstWins
+
+// FirstWins is a [ConflictResolver] that keeps the File that was added first,
+// discarding later Files at the same path.
+func FirstWins(existing, _ File) (File, error) {
+	return existing, nil
+}
+
+// LastWins is a [ConflictResolver] that keeps the File that was added last,
+// replacing earlier Files at the same path.
+func LastWins(_, incoming File) (File, error) {
+	return incoming, nil
+}
+
+// IdenticalContent is a [ConflictResolver] that allows multiple Files at the
+// same path if they have identical Data, Mode and Policy, such as a shared
+// doc.go emitted by several jennies. The File that was added first is kept.
+// Files that differ are an error.
+func IdenticalContent(existing, incoming File) (File, error) {
+	if !bytes.Equal(existing.Data, incoming.Data) {
+		return File{}, errors.New("contents differ")
+	}
+	if existing.Mode != incoming.Mode {
+		return File{}, fmt.Errorf("modes differ, %s and %s", existing.Mode, incoming.Mode)
+	}
+	if existing.Policy != incoming.Policy {
+		return File{}, fmt.Errorf("policies differ, %s and %s", existing.Policy, incoming.Policy)
+	}
+	return existing, nil
+}
+
+// resolveConflict combines existing and incoming using r, validating the
+// result.
+func resolveConflict(r ConflictResolver, existing, incoming File) (File, error) {
+	f, err := r(existing, incoming)
+	if err != nil {
+		return File{}, fmt.Errorf("cannot create %s for jenny %q, path already created by jenny %q: %w", incoming.RelativePath, jennystack(incoming.From), jennystack(existing.From), err)
+	}
+	if f.RelativePath != existing.RelativePath {
+		return File{}, fmt.Errorf("resolving conflict at %s between jennies %q and %q changed path to %s", existing.RelativePath, jennystack(existing.From), jennystack(incoming.From), f.RelativePath)
+	}
+	if err := f.Validate(); err != nil {
+		return File{}, err
+	}
+	return f, nil
+}
+
+// Resolve is like [Files.Validate], but combines Files having the same
+// RelativePath using the provided [ConflictResolver] instead of treating them
+// as invalid. The combined File takes the position of the first File at its
+// path. If r is nil, Resolve returns the receiver if it is valid.
+func (fsl Files) Resolve(r ConflictResolver) (Files, error) {
+	if r == nil {
+		if err := fsl.Validate(); err != nil {
+			return nil, err
+		}
+		return fsl, nil
+	}
+
+	var result *multierror.Error
+	out := make(Files, 0, len(fsl))
+	idx := make(map[string]int, len(fsl))
+	for _, f := range fsl {
+		if err := f.Validate(); err != nil {
+			result = multierror.Append(result, err)
+		} else if !f.Exists() {
+			result = multierror.Append(result, fmt.Errorf(`nonexistent File (RelativePath == "") not allowed within Files slice`))
+		} else if i, has := idx[f.RelativePath]; has {
+			rf, err := resolveConflict(r, out[i], f)
+			if err != nil {
+				result = multierror.Append(result, err)
+			} else {
+				out[i] = rf
+			}
+		} else {
+			idx[f.RelativePath] = len(out)
+			out = append(out, f)
+		}
+	}
+	if result.ErrorOrNil() != nil {
+		return nil, result
+	}
+	return out, nil
+}
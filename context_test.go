@@ -0,0 +1,115 @@
+package codejen
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type valueKey struct{}
+
+// valueFile returns a File at the path formed by joining ins, containing the
+// value of valueKey in ctx.
+func valueFile(ctx context.Context, ins ...string) File {
+	data, _ := ctx.Value(valueKey{}).(string)
+	return File{
+		RelativePath: strings.Join(ins, "+"),
+		Data:         []byte(data),
+		From:         []NamedJenny{testJenny("ctx")},
+	}
+}
+
+// The ctx* jennies implement both the context-free and context-aware form of
+// each jenny interface, generating a valueFile. Generate uses an empty context.
+
+type ctxO2O struct{ testJenny }
+
+func (ctxO2O) Generate(in string) (*File, error) {
+	f := valueFile(context.Background(), in)
+	return &f, nil
+}
+
+func (ctxO2O) GenerateContext(ctx context.Context, in string) (*File, error) {
+	f := valueFile(ctx, in)
+	return &f, nil
+}
+
+type ctxO2M struct{ testJenny }
+
+func (ctxO2M) Generate(in string) (Files, error) {
+	return Files{valueFile(context.Background(), in)}, nil
+}
+
+func (ctxO2M) GenerateContext(ctx context.Context, in string) (Files, error) {
+	return Files{valueFile(ctx, in)}, nil
+}
+
+type ctxM2O struct{ testJenny }
+
+func (ctxM2O) Generate(ins ...string) (*File, error) {
+	f := valueFile(context.Background(), ins...)
+	return &f, nil
+}
+
+func (ctxM2O) GenerateContext(ctx context.Context, ins ...string) (*File, error) {
+	f := valueFile(ctx, ins...)
+	return &f, nil
+}
+
+type ctxM2M struct{ testJenny }
+
+func (ctxM2M) Generate(ins ...string) (Files, error) {
+	return Files{valueFile(context.Background(), ins...)}, nil
+}
+
+func (ctxM2M) GenerateContext(ctx context.Context, ins ...string) (Files, error) {
+	return Files{valueFile(ctx, ins...)}, nil
+}
+
+func TestWrappersForwardContext(t *testing.T) {
+	all := func(string) bool { return true }
+	first := func(s string) string { return s[:1] }
+
+	tt := []struct {
+		name  string
+		jenny Jenny[string]
+		want  map[string]string
+	}{
+		{name: "MapOneToOne", jenny: MapOneToOne[string](ctxO2O{"A"}), want: map[string]string{"a1": "v", "a2": "v", "b1": "v"}},
+		{name: "FilterOneToOne", jenny: FilterOneToOne[string](ctxO2O{"A"}, all), want: map[string]string{"a1": "v", "a2": "v", "b1": "v"}},
+		{name: "MapOneToMany", jenny: MapOneToMany[string](ctxO2M{"A"}), want: map[string]string{"a1": "v", "a2": "v", "b1": "v"}},
+		{name: "FilterOneToMany", jenny: FilterOneToMany[string](ctxO2M{"A"}, all), want: map[string]string{"a1": "v", "a2": "v", "b1": "v"}},
+		{name: "MapManyToOne", jenny: MapManyToOne[string](ctxM2O{"A"}), want: map[string]string{"a1+a2+b1": "v"}},
+		{name: "FilterManyToOne", jenny: FilterManyToOne[string](ctxM2O{"A"}, all), want: map[string]string{"a1+a2+b1": "v"}},
+		{name: "MapManyToMany", jenny: MapManyToMany[string](ctxM2M{"A"}), want: map[string]string{"a1+a2+b1": "v"}},
+		{name: "FilterManyToMany", jenny: FilterManyToMany[string](ctxM2M{"A"}, all), want: map[string]string{"a1+a2+b1": "v"}},
+		{name: "OneToOneAsOneToMany", jenny: OneToOneAsOneToMany[string](ctxO2O{"A"}), want: map[string]string{"a1": "v", "a2": "v", "b1": "v"}},
+		{name: "OneToOneAsManyToMany", jenny: OneToOneAsManyToMany[string](ctxO2O{"A"}), want: map[string]string{"a1": "v", "a2": "v", "b1": "v"}},
+		{name: "OneToManyAsManyToMany", jenny: OneToManyAsManyToMany[string](ctxO2M{"A"}), want: map[string]string{"a1": "v", "a2": "v", "b1": "v"}},
+		{name: "ManyToOneAsManyToMany", jenny: ManyToOneAsManyToMany[string](ctxM2O{"A"}), want: map[string]string{"a1+a2+b1": "v"}},
+		{name: "GroupManyToOne", jenny: GroupManyToOne[string](ctxM2O{"A"}, first), want: map[string]string{"a1+a2": "v", "b1": "v"}},
+		{
+			name:  "nested",
+			jenny: OneToOneAsManyToMany(FilterOneToOne(MapOneToOne[string](ctxO2O{"A"}), all)),
+			want:  map[string]string{"a1": "v", "a2": "v", "b1": "v"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			jl := JennyListWithNamer(func(s string) string { return s })
+			jl.Append(tc.jenny)
+
+			ctx := context.WithValue(context.Background(), valueKey{}, "v")
+			jfs, err := jl.GenerateFSContext(ctx, "a1", "a2", "b1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, fsContents(jfs)); diff != "" {
+				t.Errorf("context not passed through (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package codejen
 
 import (
 	"fmt"
+	"io/fs"
 	"path/filepath"
 	"strings"
 
@@ -35,12 +36,88 @@ type File struct {
 	// From is the stack of jennies responsible for producing this File.
 	// Wrapper jennies should precede the jennies they wrap.
 	From []NamedJenny
+
+	// Mode is the permissions of the file, e.g. 0755 for an executable script.
+	// Only permission bits are allowed.
+	//
+	// If zero, [FS.Write] uses its default permissions for new files and
+	// preserves the permissions of existing files, and [FS.Verify] does not
+	// check permissions.
+	Mode fs.FileMode
+
+	// Policy determines whether [FS.Write] may overwrite the file if it
+	// already exists. The zero value, [PolicyOverwrite], always overwrites it.
+	Policy WritePolicy
+
+	// Regions enables protected regions in the file, whose contents are
+	// written by humans and preserved by [FS.Write]. See [RegionBeginMarker].
+	Regions bool
+}
+
+// WritePolicy determines whether [FS.Write] overwrites an existing file, and
+// what [FS.Verify] checks about it.
+type WritePolicy int
+
+const (
+	// PolicyOverwrite always overwrites the file with its generated contents,
+	// and Verify checks that its contents are as generated. This is the
+	// default.
+	PolicyOverwrite WritePolicy = iota
+
+	// PolicyCreateOnly writes the file only if it does not already exist, as
+	// for scaffolding that is generated once and then owned by humans. Verify
+	// only checks that the file exists.
+	PolicyCreateOnly
+
+	// PolicyKeepIfModified overwrites the file unless it was modified since it
+	// was last written. Modification is determined by comparing the file to the
+	// checksum recorded in the manifest of the previous Write, and so requires
+	// manifest mode ([WithManifest]); without a manifest, or if the file is not
+	// in the previous manifest, any existing file that differs from its
+	// generated contents is considered modified. Verify only checks the contents
+	// of files that were not modified.
+	PolicyKeepIfModified
+)
+
+func (p WritePolicy) String() string {
+	switch p {
+	case PolicyOverwrite:
+		return "overwrite"
+	case PolicyCreateOnly:
+		return "create-only"
+	case PolicyKeepIfModified:
+		return "keep-if-modified"
+	default:
+		return fmt.Sprintf("WritePolicy(%d)", int(p))
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (p WritePolicy) MarshalText() ([]byte, error) {
+	if p < PolicyOverwrite || p > PolicyKeepIfModified {
+		return nil, fmt.Errorf("unknown WritePolicy %d", int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (p *WritePolicy) UnmarshalText(text []byte) error {
+	for np := PolicyOverwrite; np <= PolicyKeepIfModified; np++ {
+		if string(text) == np.String() {
+			*p = np
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown WritePolicy %q", text)
 }
 
 func (f File) toMapFile() *mapFile {
 	return &mapFile{
-		Data: f.Data,
-		Sys:  f.From,
+		Data:    f.Data,
+		Mode:    f.Mode,
+		Sys:     f.From,
+		policy:  f.Policy,
+		regions: f.Regions,
 	}
 }
 
@@ -49,8 +126,9 @@ func (f File) Exists() bool {
 	return f.RelativePath != ""
 }
 
-// Validate checks that the File is valid - has a relative path, and at least
-// one jenny in its From.
+// Validate checks that the File is valid - has a relative path, at least
+// one jenny in its From, a Mode containing only permission bits, and a known
+// Policy.
 func (f File) Validate() error {
 	if !f.Exists() {
 		return nil
@@ -62,6 +140,12 @@ func (f File) Validate() error {
 	if len(f.From) == 0 {
 		return fmt.Errorf("%s: File must have at least one From jenny", f.RelativePath)
 	}
+	if f.Mode&^fs.ModePerm != 0 {
+		return fmt.Errorf("%s: File Mode may only contain permission bits, got %s", f.RelativePath, f.Mode)
+	}
+	if f.Policy < PolicyOverwrite || f.Policy > PolicyKeepIfModified {
+		return fmt.Errorf("%s: unknown File Policy %s", f.RelativePath, f.Policy)
+	}
 	return nil
 }
 
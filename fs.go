@@ -1,6 +1,7 @@
 package codejen
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/go-multierror"
@@ -28,32 +30,135 @@ import (
 //
 // FS behaves like an immutable append-only data structure - [File]s may not be
 // removed once [FS.Add]ed. If a path conflict occurs when adding a new file or
-// merging another FS, an error is returned.
+// merging another FS, an error is returned, unless a [ConflictResolver] is set
+// with [FS.SetConflictResolver].
 //
 // Every File added to FS must have a relative path. An absolute path may be
 // provided as a universal prefix on calls to FS.Write or FS.Verify.
 //
+// FS.Write and FS.Verify operate on the real filesystem by default. Other
+// filesystems, such as an in-memory [MemTarget], may be used via [WithTarget].
+//
 // FS implements [io/fs.FS], backed by [fstest.MapFS]. Added mutexes make FS
 // safe for concurrent use, but it has the same scaling limitations as
 // [fstest.MapFS] for large numbers of files.
 //
 // Note that the statelessness of FS entails that if a particular Jenny Input
 // goes away, FS.Verify cannot know what orphaned generated files should be
-// removed.
+// removed. Manifest mode ([WithManifest]) addresses this by recording the
+// emitted paths on disk, for use by [FS.Verify] and [FS.Prune].
 type FS struct {
 	mapFS
 	mu sync.RWMutex
+
+	// resolver, if non-nil, combines Files added at the same path.
+	resolver ConflictResolver
 }
 
 // ShouldExistErr is an error that indicates a file should exist, but does not.
 type ShouldExistErr struct {
-	// TODO
+	// Path is the absolute path at which the file should exist, or the path
+	// within the Target for targets other than [OSTarget].
+	Path string
+
+	// RelativePath is the path of the file within the FS.
+	RelativePath string
+
+	// From is the stack of jennies responsible for producing the file.
+	From []NamedJenny
+}
+
+func (e *ShouldExistErr) Error() string {
+	return fmt.Sprintf("%s: generated file should exist, but does not", e.Path)
 }
 
 // ContentsDifferErr is an error that indicates the contents of a file on disk are
 // different than those in the FS.
 type ContentsDifferErr struct {
-	// TODO
+	// Path is the absolute path of the file on disk, or the path within the
+	// Target for targets other than [OSTarget].
+	Path string
+
+	// RelativePath is the path of the file within the FS.
+	RelativePath string
+
+	// From is the stack of jennies responsible for producing the file.
+	From []NamedJenny
+
+	// Expected is the contents of the file in the FS.
+	Expected []byte
+
+	// Actual is the contents of the file on disk.
+	Actual []byte
+
+	// Diff is a human-readable diff from Actual to Expected.
+	Diff string
+}
+
+func (e *ContentsDifferErr) Error() string {
+	return fmt.Sprintf("%s would have changed:\n\n%s", e.Path, e.Diff)
+}
+
+// ModeDiffersErr is an error that indicates the permissions of a file on disk
+// are different than the [File.Mode] in the FS.
+type ModeDiffersErr struct {
+	// Path is the absolute path of the file on disk, or the path within the
+	// Target for targets other than [OSTarget].
+	Path string
+
+	// RelativePath is the path of the file within the FS.
+	RelativePath string
+
+	// From is the stack of jennies responsible for producing the file.
+	From []NamedJenny
+
+	// Expected is the Mode of the file in the FS.
+	Expected os.FileMode
+
+	// Actual is the permissions of the file on disk.
+	Actual os.FileMode
+}
+
+func (e *ModeDiffersErr) Error() string {
+	return fmt.Sprintf("%s: permissions would have changed from %s to %s", e.Path, e.Actual, e.Expected)
+}
+
+// OrphanedFileErr is an error that indicates a file recorded in the manifest
+// of a previous [FS.Write] is no longer generated, but still exists on disk.
+type OrphanedFileErr struct {
+	// Path is the absolute path of the file on disk, or the path within the
+	// Target for targets other than [OSTarget].
+	Path string
+
+	// RelativePath is the path of the file, relative to the prefix.
+	RelativePath string
+
+	// From is the jenny stack that produced the file, as recorded in the
+	// manifest.
+	From string
+}
+
+func (e *OrphanedFileErr) Error() string {
+	return fmt.Sprintf("%s: orphaned file from jenny %q should be removed, but still exists", e.Path, e.From)
+}
+
+// HandEditedErr is an error that indicates a generated file was edited by hand
+// since it was last written, and so was not overwritten by [FS.Write]. See
+// [WithEditCheck].
+type HandEditedErr struct {
+	// Path is the absolute path of the file on disk, or the path within the
+	// Target for targets other than [OSTarget].
+	Path string
+
+	// RelativePath is the path of the file within the FS.
+	RelativePath string
+
+	// From is the stack of jennies responsible for producing the file.
+	From []NamedJenny
+}
+
+func (e *HandEditedErr) Error() string {
+	return fmt.Sprintf("%s: file was edited by hand since it was generated, refusing to overwrite", e.Path)
 }
 
 type jennystack []NamedJenny
@@ -76,71 +181,523 @@ func NewFS() *FS {
 // Verify checks the contents of each file against the filesystem. It emits an error
 // if any of its contained files differ.
 //
+// The returned error is a [*multierror.Error] containing a [*ShouldExistErr]
+// for each missing file, a [*ContentsDifferErr] for each file whose contents
+// differ, and a [*ModeDiffersErr] for each file with a non-zero [File.Mode]
+// whose permissions differ, or an error describing an IO failure. Errors are
+// sorted by path, regardless of the order in which files were verified.
+//
+// Files with a [File.Policy] of [PolicyCreateOnly] are only checked for
+// existence, as are those with [PolicyKeepIfModified] that were modified.
+//
+// Differences within protected regions of Files with [File.Regions] set are
+// ignored. An [*OrphanedRegionErr] is emitted for each region in a file on disk
+// that is no longer present in the generated file, and an [*InvalidRegionsErr]
+// for each file whose regions are malformed.
+//
 // If the provided prefix path is non-empty, it will be prepended to all file
 // entries in the map for writing. prefix may be an absolute path.
-func (fs *FS) Verify(ctx context.Context, prefix string) error {
-	g, _ := errgroup.WithContext(ctx)
-	g.SetLimit(12)
-	var result *multierror.Error
+//
+// In manifest mode ([WithManifest]), Verify also checks that the manifest is up
+// to date, and emits an [*OrphanedFileErr] for each orphaned file - a file
+// recorded in the previous manifest that is no longer generated, but still
+// exists. Orphaned files that [FS.Prune] would keep are instead reported as
+// [StateKept].
+//
+// The returned [WriteReport] describes the state of each verified file. If an
+// IO error occurs, the report covers only the files verified before the error.
+//
+// Verify stops at the first IO error, or when ctx is done, in which case
+// ctx.Err() is returned.
+func (fs *FS) Verify(ctx context.Context, prefix string, opts ...FSOption) (*WriteReport, error) {
+	start := time.Now()
+	o := newFSOptions(opts...)
+	files, err := o.withManifest(fs.AsFiles())
+	if err != nil {
+		return newWriteReport(start, nil), err
+	}
 
-	for _, it := range fs.AsFiles() {
-		item := it
-		g.Go(func() error {
-			ipath := filepath.Join(prefix, item.RelativePath)
-			if _, err := os.Stat(ipath); err != nil {
-				if errors.Is(err, os.ErrNotExist) {
-					result = multierror.Append(result, fmt.Errorf("%s: generated file should exist, but does not", ipath))
-				} else {
-					return fmt.Errorf("%s: could not stat generated file: %w", ipath, err)
-				}
-				return nil
-			}
+	prev, err := o.previousManifest(prefix)
+	if err != nil {
+		return newWriteReport(start, nil), fmt.Errorf("io error while verifying tree: %w", err)
+	}
+	orphans := prev.orphans(files)
+	sums := prev.sums()
+	entries := make([]ReportEntry, len(files), len(files)+len(orphans))
+	findings := make([]verifyFindings, len(files), len(files)+len(orphans))
+	for _, orphan := range orphans {
+		if err := ctx.Err(); err != nil {
+			return newWriteReport(start, entries), err
+		}
 
-			ob, err := os.ReadFile(ipath) //nolint:gosec
+		ostart := time.Now()
+		rel := filepath.FromSlash(orphan.Path)
+		opath := filepath.Join(prefix, rel)
+		_, serr := o.target.Stat(opath)
+		if serr == nil {
+			owned, err := o.ownedOrphan(opath, orphan)
 			if err != nil {
-				return fmt.Errorf("%s: error reading file: %w", ipath, err)
+				return newWriteReport(start, entries), fmt.Errorf("io error while verifying tree: %w", err)
 			}
-			dstr := cmp.Diff(string(ob), string(item.Data))
-			if dstr != "" {
-				result = multierror.Append(result, fmt.Errorf("%s would have changed:\n\n%s", ipath, dstr))
+			if owned {
+				entries = append(entries, ReportEntry{
+					Path:     rel,
+					State:    StateKept,
+					From:     orphan.From,
+					Duration: time.Since(ostart),
+				})
+				continue
 			}
+			findings = append(findings, verifyFindings{
+				path: rel,
+				errs: []error{&OrphanedFileErr{
+					Path:         o.abspath(opath),
+					RelativePath: rel,
+					From:         orphan.From,
+				}},
+			})
+			entries = append(entries, ReportEntry{
+				Path:     rel,
+				State:    StateOrphaned,
+				From:     orphan.From,
+				Duration: time.Since(ostart),
+			})
+		} else if !errors.Is(serr, os.ErrNotExist) {
+			return newWriteReport(start, entries), fmt.Errorf("io error while verifying tree: %s: could not stat orphaned file: %w", opath, serr)
+		}
+	}
+
+	err = o.forEachFile(ctx, files, func(idx int, item File) error {
+		fstart := time.Now()
+		ipath := filepath.Join(prefix, item.RelativePath)
+		if err := o.checkSymlink(ipath); err != nil {
+			return err
+		}
+		fi, err := o.target.Stat(ipath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				findings[idx].add(item.RelativePath, &ShouldExistErr{
+					Path:         o.abspath(ipath),
+					RelativePath: item.RelativePath,
+					From:         item.From,
+				})
+				entries[idx] = item.entry(StateMissing, fstart)
+			} else {
+				return fmt.Errorf("%s: could not stat generated file: %w", ipath, err)
+			}
+			return nil
+		}
+
+		if item.Policy == PolicyCreateOnly {
+			entries[idx] = item.entry(StateKept, fstart)
+			return nil
+		}
+
+		ob, err := o.target.ReadFile(ipath)
+		if err != nil {
+			return fmt.Errorf("%s: error reading file: %w", ipath, err)
+		}
+		if item.keep(sums, ob) {
+			entries[idx] = item.entry(StateKept, fstart)
+			return nil
+		}
+		state := StateUnchanged
+		expected, orphans, err := item.mergeRegions(ob)
+		if err != nil {
+			findings[idx].add(item.RelativePath, &InvalidRegionsErr{
+				Path:         o.abspath(ipath),
+				RelativePath: item.RelativePath,
+				From:         item.From,
+				Err:          err,
+			})
+			entries[idx] = item.entry(StateDiffers, fstart)
+			return nil
+		}
+		for _, name := range orphans {
+			findings[idx].add(item.RelativePath, &OrphanedRegionErr{
+				Path:         o.abspath(ipath),
+				RelativePath: item.RelativePath,
+				From:         item.From,
+				Region:       name,
+			})
+			state = StateDiffers
+		}
+		dstr := cmp.Diff(string(ob), string(expected))
+		if dstr != "" {
+			findings[idx].add(item.RelativePath, &ContentsDifferErr{
+				Path:         o.abspath(ipath),
+				RelativePath: item.RelativePath,
+				From:         item.From,
+				Expected:     expected,
+				Actual:       ob,
+				Diff:         dstr,
+			})
+			state = StateDiffers
+		}
+		if item.Mode != 0 && fi.Mode().Perm() != item.Mode {
+			findings[idx].add(item.RelativePath, &ModeDiffersErr{
+				Path:         o.abspath(ipath),
+				RelativePath: item.RelativePath,
+				From:         item.From,
+				Expected:     item.Mode,
+				Actual:       fi.Mode().Perm(),
+			})
+			state = StateDiffers
+		}
+		entries[idx] = item.entry(state, fstart)
+		return nil
+	})
+	if cerr := ctx.Err(); cerr != nil {
+		return newWriteReport(start, entries), cerr
+	}
+	if err != nil {
+		return newWriteReport(start, entries), fmt.Errorf("io error while verifying tree: %w", err)
+	}
+
+	return newWriteReport(start, entries), sortFindings(findings).ErrorOrNil()
+}
+
+// keep reports whether the existing contents of the file should be left in
+// place, per its Policy. sums are the checksums recorded in the previous
+// manifest, if any.
+func (f File) keep(sums map[string]string, existing []byte) bool {
+	switch f.Policy {
+	case PolicyCreateOnly:
+		return true
+	case PolicyKeepIfModified:
+		esum := checksum(existing, f.Regions)
+		if esum == checksum(f.Data, f.Regions) {
+			return false
+		}
+		sum, has := sums[filepath.ToSlash(f.RelativePath)]
+		return !has || esum != sum
+	default:
+		return false
+	}
+}
+
+// checkEdits returns a [*HandEditedErr] for each of the provided files that
+// was edited by hand since the previous Write, per [WithEditCheck], or an error
+// describing an IO failure.
+func (o *fsOptions) checkEdits(ctx context.Context, prefix string, files []File, sums map[string]string) error {
+	findings := make([]verifyFindings, len(files))
+	err := o.forEachFile(ctx, files, func(idx int, item File) error {
+		sum, has := sums[filepath.ToSlash(item.RelativePath)]
+		if !has || item.Policy != PolicyOverwrite {
 			return nil
+		}
+		path := filepath.Join(prefix, item.RelativePath)
+		ob, err := o.target.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("%s: error reading file: %w", path, err)
+		}
+		if osum := checksum(ob, item.Regions); osum != sum && osum != checksum(item.Data, item.Regions) {
+			findings[idx].add(item.RelativePath, &HandEditedErr{
+				Path:         o.abspath(path),
+				RelativePath: item.RelativePath,
+				From:         item.From,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return sortFindings(findings).ErrorOrNil()
+}
+
+// forEachFile calls fn for each of files, concurrently per [WithConcurrency].
+// Each call only touches state at its own index, so no synchronization is
+// needed. forEachFile returns the first error from fn, or ctx.Err() if ctx is
+// done.
+func (o *fsOptions) forEachFile(ctx context.Context, files []File, fn func(idx int, f File) error) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(o.concurrency)
+	for i, it := range files {
+		if gctx.Err() != nil {
+			// Either ctx was cancelled, or a worker failed; no point scheduling more.
+			break
+		}
+		idx, item := i, it
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				return err
+			}
+			return fn(idx, item)
 		})
 	}
 	err := g.Wait()
-	if err != nil {
-		return fmt.Errorf("io error while verifying tree: %w", err)
+	if cerr := ctx.Err(); cerr != nil {
+		return cerr
 	}
+	return err
+}
+
+// verifyFindings are the verification failures for a single path.
+type verifyFindings struct {
+	path string
+	errs []error
+}
+
+func (vf *verifyFindings) add(path string, err error) {
+	vf.path = path
+	vf.errs = append(vf.errs, err)
+}
 
-	return result.ErrorOrNil()
+// sortFindings combines all findings into a single error, ordered by path so
+// that output is stable regardless of the order in which files were verified.
+func sortFindings(findings []verifyFindings) *multierror.Error {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].path < findings[j].path
+	})
+
+	var result *multierror.Error
+	for _, vf := range findings {
+		for _, err := range vf.errs {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result
 }
 
-// Write writes all of the files to their indicated paths.
+// Write writes all of the files to their indicated paths. Files whose
+// contents and permissions on disk are already identical to those in the FS
+// are not written, leaving their modification times untouched.
+//
+// Existing files are left in place if their [File.Policy] says so, and are
+// reported as [StateKept]. Use [WithEditCheck] to also protect files that were
+// edited by hand.
+//
+// For Files with [File.Regions] set, the contents of protected regions in
+// existing files are carried over into the written files. The contents of
+// regions no longer present in the generated file are discarded; use
+// [FS.Verify] to detect such regions beforehand. If the regions of an existing
+// file are malformed, Write fails with an [*InvalidRegionsErr].
 //
 // If the provided prefix path is non-empty, it will be prepended to all file
 // entries in the map for writing. prefix may be an absolute path.
-// TODO try to undo already-written files on error (only best effort, it's impossible to guarantee)
-func (fs *FS) Write(ctx context.Context, prefix string) error {
-	g, _ := errgroup.WithContext(ctx)
-	g.SetLimit(12)
+//
+// In manifest mode ([WithManifest]), Write also writes a manifest recording
+// every emitted path. Write does not remove orphaned files; call [FS.Prune]
+// before Write, as Write replaces the previous manifest.
+//
+// By default, Write leaves already-written files in place if an error occurs.
+// Use [WithAtomic] to make Write all-or-nothing.
+//
+// The returned [WriteReport] describes which files were created, updated, or
+// left unchanged. If an error occurs, the report covers only the files
+// processed before the error, and is empty in atomic mode, where a failed Write
+// makes no changes. In atomic mode, if all files were committed but removing
+// backups of the original files fails, the full report is returned along with
+// an error describing only the failed cleanup.
+//
+// Write stops at the first IO error, or when ctx is done, in which case
+// ctx.Err() is returned.
+func (fs *FS) Write(ctx context.Context, prefix string, opts ...FSOption) (*WriteReport, error) {
+	start := time.Now()
+	o := newFSOptions(opts...)
+	files, err := o.withManifest(fs.AsFiles())
+	if err != nil {
+		return newWriteReport(start, nil), err
+	}
+	prev, err := o.previousManifest(prefix)
+	if err != nil {
+		return newWriteReport(start, nil), err
+	}
+	sums := prev.sums()
+	if o.editcheck {
+		if o.manifest == "" {
+			return newWriteReport(start, nil), errors.New("checking for hand-edited files requires manifest mode")
+		}
+		if !o.force {
+			if err := o.checkEdits(ctx, prefix, files, sums); err != nil {
+				return newWriteReport(start, nil), err
+			}
+		}
+	}
 
-	for _, item := range fs.AsFiles() {
-		it := item
-		g.Go(func() error {
-			path := filepath.Join(prefix, it.RelativePath)
-			err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	var tx *txn
+	if o.atomic {
+		tx = &txn{opts: o}
+	}
+
+	entries := make([]ReportEntry, len(files))
+	err = o.forEachFile(ctx, files, func(idx int, it File) error {
+		fstart := time.Now()
+		path := filepath.Join(prefix, it.RelativePath)
+		if err := o.checkSymlink(path); err != nil {
+			return err
+		}
+		perm := o.fileMode
+		if it.Mode != 0 {
+			perm = it.Mode
+		}
+		state := StateCreated
+		data := it.Data
+		fi, err := o.target.Stat(path)
+		if err == nil && it.Policy == PolicyCreateOnly {
+			// Owned by humans once it exists, so not even read.
+			entries[idx] = it.entry(StateKept, fstart)
+			return nil
+		}
+		var ob []byte
+		if err == nil {
+			ob, err = o.target.ReadFile(path)
+		}
+		if err == nil {
+			// Files left in place by their policy are never merged, as their
+			// contents need not contain valid regions.
+			if it.keep(sums, ob) {
+				entries[idx] = it.entry(StateKept, fstart)
+				return nil
+			}
+			data, _, err = it.mergeRegions(ob)
 			if err != nil {
-				return fmt.Errorf("%s: failed to ensure parent directory exists: %w", path, err)
+				return &InvalidRegionsErr{
+					Path:         o.abspath(path),
+					RelativePath: it.RelativePath,
+					From:         it.From,
+					Err:          err,
+				}
+			}
+		}
+		switch {
+		case err == nil && bytes.Equal(ob, data) && (it.Mode == 0 || fi.Mode().Perm() == it.Mode):
+			entries[idx] = it.entry(StateUnchanged, fstart)
+			return nil
+		case err == nil:
+			state = StateUpdated
+			if it.Mode == 0 {
+				perm = fi.Mode().Perm()
 			}
+		case !errors.Is(err, os.ErrNotExist):
+			return fmt.Errorf("%s: error reading file: %w", path, err)
+		}
 
-			if err := os.WriteFile(path, it.Data, 0644); err != nil {
-				return fmt.Errorf("%s: error while writing file: %w", path, err)
+		if tx != nil {
+			// Renaming onto a symlink would replace the link, rather than
+			// writing through it as in non-atomic mode.
+			rpath, err := o.resolveSymlink(path)
+			if err != nil {
+				return err
+			}
+			if err := tx.stage(rpath, data, perm); err != nil {
+				return err
 			}
+			entries[idx] = it.entry(state, fstart)
 			return nil
+		}
+
+		err = o.target.MkdirAll(filepath.Dir(path), o.dirMode)
+		if err != nil {
+			return fmt.Errorf("%s: failed to ensure parent directory exists: %w", path, err)
+		}
+
+		if err := o.writeFile(path, data, perm); err != nil {
+			return fmt.Errorf("%s: error while writing file: %w", path, err)
+		}
+		// Permissions of existing files are not changed by writing, and those of
+		// new files are subject to umask.
+		if it.Mode != 0 {
+			if err := o.target.Chmod(path, it.Mode); err != nil {
+				return fmt.Errorf("%s: error while setting permissions: %w", path, err)
+			}
+		}
+		entries[idx] = it.entry(state, fstart)
+		return nil
+	})
+	if tx != nil {
+		if err != nil {
+			return newWriteReport(start, nil), tx.rollback(err)
+		}
+		if err = tx.commit(ctx); err != nil {
+			return newWriteReport(start, nil), err
+		}
+		// All files are in place, so the Write succeeded even if leftover
+		// backups of original files could not be removed.
+		return newWriteReport(start, entries), tx.cleanup()
+	}
+	return newWriteReport(start, entries), err
+}
+
+// Prune removes orphaned files - files recorded in the manifest written by a
+// previous call to [FS.Write] that are no longer contained in the FS. Prune
+// requires manifest mode ([WithManifest]), and must be called before FS.Write,
+// as Write replaces the previous manifest.
+//
+// Orphaned files owned by humans are never removed: those with a [File.Policy]
+// of [PolicyCreateOnly], those with [PolicyKeepIfModified] that were modified
+// since they were written, and, per [WithEditCheck] unless forced, any other
+// files edited by hand. They are listed in the returned [WriteReport] as
+// [StateKept].
+//
+// Directories left empty by the removal of orphaned files are also removed. The
+// returned WriteReport lists each removed file as [StatePruned].
+func (fs *FS) Prune(ctx context.Context, prefix string, opts ...FSOption) (*WriteReport, error) {
+	start := time.Now()
+	o := newFSOptions(opts...)
+	if o.manifest == "" {
+		return newWriteReport(start, nil), errors.New("Prune requires manifest mode")
+	}
+
+	prev, err := o.previousManifest(prefix)
+	if err != nil {
+		return newWriteReport(start, nil), err
+	}
+	files, err := o.withManifest(fs.AsFiles())
+	if err != nil {
+		return newWriteReport(start, nil), err
+	}
+
+	var entries []ReportEntry
+	for _, orphan := range prev.orphans(files) {
+		if err := ctx.Err(); err != nil {
+			return newWriteReport(start, entries), err
+		}
+
+		ostart := time.Now()
+		rel := filepath.FromSlash(orphan.Path)
+		opath := filepath.Join(prefix, rel)
+		owned, err := o.ownedOrphan(opath, orphan)
+		if err != nil {
+			return newWriteReport(start, entries), err
+		}
+		if owned {
+			if _, err := o.target.Stat(opath); err == nil {
+				entries = append(entries, ReportEntry{
+					Path:     rel,
+					State:    StateKept,
+					From:     orphan.From,
+					Duration: time.Since(ostart),
+				})
+			}
+			continue
+		}
+		if err := o.target.Remove(opath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return newWriteReport(start, entries), fmt.Errorf("%s: error while removing orphaned file: %w", opath, err)
+		}
+
+		// Best effort - removal fails on the first non-empty directory.
+		for dir := filepath.Dir(rel); dir != "."; dir = filepath.Dir(dir) {
+			if o.target.Remove(filepath.Join(prefix, dir)) != nil {
+				break
+			}
+		}
+		entries = append(entries, ReportEntry{
+			Path:     rel,
+			State:    StatePruned,
+			From:     orphan.From,
+			Duration: time.Since(ostart),
 		})
 	}
 
-	return g.Wait()
+	return newWriteReport(start, entries), nil
 }
 
 // AsFiles returns a Files representing the contents of the FS.
@@ -167,8 +724,18 @@ func (fs *FS) AsFiles() []File {
 	return sl
 }
 
+// SetConflictResolver sets the [ConflictResolver] used to combine Files having
+// the same RelativePath in [FS.Add] and [FS.Merge], and inherited by the FS
+// returned from [FS.Map]. By default, conflicts are an error.
+func (fs *FS) SetConflictResolver(r ConflictResolver) {
+	fs.mu.Lock()
+	fs.resolver = r
+	fs.mu.Unlock()
+}
+
 // Add adds one or more files to the FS. An error is returned if the
-// RelativePath of any provided files already exists in the FS.
+// RelativePath of any provided files already exists in the FS, or is shared
+// by more than one of them, unless a [ConflictResolver] is set.
 func (fs *FS) Add(flist ...File) error {
 	fs.mu.Lock()
 	err := fs.add(flist...)
@@ -177,7 +744,8 @@ func (fs *FS) Add(flist ...File) error {
 }
 
 func (fs *FS) add(flist ...File) error {
-	if err := Files(flist).Validate(); err != nil {
+	flist, err := Files(flist).Resolve(fs.resolver)
+	if err != nil {
 		return err
 	}
 
@@ -187,11 +755,20 @@ func (fs *FS) add(flist ...File) error {
 func (fs *FS) addValidated(flist ...File) error {
 	var result *multierror.Error
 
+	resolved := make([]File, 0, len(flist))
 	for _, f := range flist {
 		if rf, has := fs.mapFS[f.RelativePath]; has {
-			result = multierror.Append(result, fmt.Errorf("cannot create %s for jenny %q, path already created by jenny %q", f.RelativePath, jennystack(f.From), stack(rf)))
+			if fs.resolver == nil {
+				result = multierror.Append(result, fmt.Errorf("cannot create %s for jenny %q, path already created by jenny %q", f.RelativePath, jennystack(f.From), stack(rf)))
+			} else if nf, err := resolveConflict(fs.resolver, toFile(f.RelativePath, rf), f); err != nil {
+				result = multierror.Append(result, err)
+			} else {
+				resolved = append(resolved, nf)
+			}
 		} else if filepath.IsAbs(f.RelativePath) {
 			result = multierror.Append(result, fmt.Errorf("files must have relative paths, got %s from %q", f.RelativePath, jennystack(f.From)))
+		} else {
+			resolved = append(resolved, f)
 		}
 	}
 
@@ -199,14 +776,16 @@ func (fs *FS) addValidated(flist ...File) error {
 		return result
 	}
 
-	for _, f := range flist {
-		fs.mapFS[f.RelativePath] = &mapFile{Data: f.Data, Sys: f.From}
+	for _, f := range resolved {
+		fs.mapFS[f.RelativePath] = f.toMapFile()
 	}
 	return nil
 }
 
 // Merge combines all the entries from the provided FS into the receiver
-// FS. Duplicate paths result in an error.
+// FS. Duplicate paths result in an error, unless the receiver has a
+// [ConflictResolver], in which case entries of the receiver are passed to it as
+// existing, and those of fs2 as incoming.
 func (fs *FS) Merge(fs2 *FS) error {
 	if fs2 == nil {
 		return nil
@@ -250,17 +829,41 @@ func (fs *FS) Map(fn FileMapper) (*FS, error) {
 		nflist = append(nflist, nf)
 	}
 	fs2 := NewFS()
+	fs.mu.RLock()
+	fs2.resolver = fs.resolver
+	fs.mu.RUnlock()
 	if err := fs2.add(nflist...); err != nil {
 		return nil, err
 	}
 	return fs2, nil
 }
 
+// mapFiles passes each of the provided Files through the stack of FileMappers,
+// in order, then pushes the wrapper jenny onto the File's From.
+func mapFiles(wrapper NamedJenny, fns []FileMapper, fl ...File) (Files, error) {
+	out := make(Files, 0, len(fl))
+	for _, f := range fl {
+		for _, fn := range fns {
+			of, err := fn(f)
+			if err != nil {
+				return nil, fmt.Errorf("postprocessing of %s from %s failed: %w", f.RelativePath, jennystack(f.From), err)
+			}
+			f = of
+		}
+		f.From = append([]NamedJenny{wrapper}, f.From...)
+		out = append(out, f)
+	}
+	return out, nil
+}
+
 func toFile(path string, mf *mapFile) File {
 	return File{
 		RelativePath: path,
 		Data:         mf.Data,
 		From:         mf.Sys.([]NamedJenny),
+		Mode:         mf.Mode,
+		Policy:       mf.policy,
+		Regions:      mf.regions,
 	}
 }
 
@@ -0,0 +1,301 @@
+package codejen
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+var (
+	_ ManyToMany[any]        = &JennyGraph[any]{}
+	_ ManyToManyContext[any] = &JennyGraph[any]{}
+)
+
+// JennyGraph is a set of jennies that may declare dependencies on each other's
+// outputs, forming a directed acyclic graph. When called, JennyGraph orders the
+// jennies topologically, and calls each jenny once all of its dependencies have
+// completed. Jennies in independent branches of the graph are called
+// concurrently.
+//
+// Jennies added with [JennyGraph.Add] take the JennyGraph's Input type. Jennies
+// added with [JennyGraph.AddDependent] take as input the Files output by the
+// jennies they depend on. As in [Pipeline], the [File.From] of input Files is
+// appended to the From of the Files a dependent jenny generates.
+//
+// Jennies are identified by the name they are added with, which must be unique
+// within the JennyGraph. Names are independent of JennyName, as wrapped jennies,
+// such as those returned from [FilterOneToOne] or [MapOneToOne], share theirs.
+// The outputs of all jennies exist in the same relative path
+// namespace, and path uniqueness is enforced across all of them.
+type JennyGraph[I Input] struct {
+	mut sync.RWMutex
+
+	// nodes, in the order they were added
+	nodes []*gnode[I]
+
+	// concurrency is the maximum number of jennies called concurrently. Values
+	// less than 1 impose no limit.
+	concurrency int
+}
+
+// gnode is a single jenny in a JennyGraph. Exactly one of root or dependent is
+// non-nil.
+type gnode[I Input] struct {
+	// name identifies the node within the JennyGraph.
+	name string
+	deps []string
+
+	root      *JennyList[I]
+	dependent *JennyList[File]
+}
+
+// NewJennyGraph creates a new, empty JennyGraph.
+func NewJennyGraph[I Input]() *JennyGraph[I] {
+	return &JennyGraph[I]{}
+}
+
+// Add adds a jenny that takes the JennyGraph's inputs, and so has no
+// dependencies, under the provided name. The JennyList in which the jenny is called is returned, to
+// allow configuring postprocessors, concurrency, an error policy or a conflict
+// resolver for it.
+//
+// The jenny must implement one of [OneToOne], [OneToMany], [ManyToOne],
+// [ManyToMany], or their context-aware variants, or this method will panic.
+func (g *JennyGraph[I]) Add(name string, j Jenny[I]) *JennyList[I] {
+	jl := &JennyList[I]{}
+	jl.Append(j)
+	g.add(&gnode[I]{
+		name: name,
+		root: jl,
+	})
+	return jl
+}
+
+// AddDependent adds a jenny under the provided name, which depends on the
+// jennies added under the names in deps. The jenny is called with the Files output by all of its dependencies, in the
+// order the dependencies are listed. As with [JennyGraph.Add], the JennyList
+// in which the jenny is called is returned.
+//
+// The jenny must implement one of [OneToOne], [OneToMany], [ManyToOne],
+// [ManyToMany], or their context-aware variants, or this method will panic.
+func (g *JennyGraph[I]) AddDependent(name string, j Jenny[File], deps ...string) *JennyList[File] {
+	jl := newFileJennyList()
+	jl.Append(j)
+	g.add(&gnode[I]{
+		name:      name,
+		deps:      deps,
+		dependent: jl,
+	})
+	return jl
+}
+
+func (g *JennyGraph[I]) add(n *gnode[I]) {
+	g.mut.Lock()
+	g.nodes = append(g.nodes, n)
+	g.mut.Unlock()
+}
+
+// SetConcurrency sets the maximum number of jennies called concurrently. By
+// default, there is no limit.
+func (g *JennyGraph[I]) SetConcurrency(n int) {
+	g.mut.Lock()
+	g.concurrency = n
+	g.mut.Unlock()
+}
+
+func (g *JennyGraph[I]) JennyName() string {
+	return fmt.Sprintf("JennyGraph[%T]", new(I))
+}
+
+// sorted returns the nodes of the graph in a topological order, with ties broken
+// by the order in which nodes were added. An error is returned if the graph has
+// duplicate or unknown jenny names, or a dependency cycle. Must be called with
+// the read lock held.
+func (g *JennyGraph[I]) sorted() ([]*gnode[I], error) {
+	byname := make(map[string]*gnode[I], len(g.nodes))
+	var result *multierror.Error
+	for _, n := range g.nodes {
+		if _, has := byname[n.name]; has {
+			result = multierror.Append(result, fmt.Errorf("multiple jennies named %q", n.name))
+		}
+		byname[n.name] = n
+	}
+	for _, n := range g.nodes {
+		for _, dep := range n.deps {
+			if _, has := byname[dep]; !has {
+				result = multierror.Append(result, fmt.Errorf("jenny %q depends on unknown jenny %q", n.name, dep))
+			}
+		}
+	}
+	if result.ErrorOrNil() != nil {
+		return nil, result
+	}
+
+	indegree := make(map[string]int, len(g.nodes))
+	dependents := make(map[string][]*gnode[I], len(g.nodes))
+	for _, n := range g.nodes {
+		indegree[n.name] = len(n.deps)
+		for _, dep := range n.deps {
+			dependents[dep] = append(dependents[dep], n)
+		}
+	}
+
+	sorted := make([]*gnode[I], 0, len(g.nodes))
+	done := make(map[string]bool, len(g.nodes))
+	for len(sorted) < len(g.nodes) {
+		progressed := false
+		for _, n := range g.nodes {
+			if done[n.name] || indegree[n.name] > 0 {
+				continue
+			}
+			done[n.name] = true
+			progressed = true
+			sorted = append(sorted, n)
+			for _, dn := range dependents[n.name] {
+				indegree[dn.name]--
+			}
+		}
+		if !progressed {
+			return nil, fmt.Errorf("dependency cycle between jennies: %s", g.cycle(byname, done))
+		}
+	}
+	return sorted, nil
+}
+
+// cycle finds a dependency cycle among the nodes not yet done, and returns it as
+// a string of the form "A -> B -> A".
+func (g *JennyGraph[I]) cycle(byname map[string]*gnode[I], done map[string]bool) string {
+	// Every remaining node has an undone dependency, so following undone
+	// dependencies from any remaining node must eventually revisit a node.
+	var start string
+	for _, n := range g.nodes {
+		if !done[n.name] {
+			start = n.name
+			break
+		}
+	}
+
+	var path []string
+	seen := make(map[string]int)
+	for name := start; ; {
+		if i, has := seen[name]; has {
+			return strings.Join(append(path[i:], name), " -> ")
+		}
+		seen[name] = len(path)
+		path = append(path, name)
+		for _, dep := range byname[name].deps {
+			if !done[dep] {
+				name = dep
+				break
+			}
+		}
+	}
+}
+
+// GenerateFS calls each jenny in the JennyGraph in dependency order, and returns
+// an FS containing all of their outputs.
+func (g *JennyGraph[I]) GenerateFS(objs ...I) (*FS, error) {
+	return g.GenerateFSContext(context.Background(), objs...)
+}
+
+// GenerateFSContext is like [JennyGraph.GenerateFS], but passes ctx to all
+// context-aware jennies. If ctx is done, no further jennies are called, and
+// ctx.Err() is returned.
+func (g *JennyGraph[I]) GenerateFSContext(ctx context.Context, objs ...I) (*FS, error) {
+	g.mut.RLock()
+	defer g.mut.RUnlock()
+
+	sorted, err := g.sorted()
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		done chan struct{}
+		fs   *FS
+		err  error
+	}
+	results := make(map[string]*result, len(sorted))
+	for _, n := range sorted {
+		results[n.name] = &result{done: make(chan struct{})}
+	}
+
+	var sem chan struct{}
+	if g.concurrency > 0 {
+		sem = make(chan struct{}, g.concurrency)
+	}
+
+	var wg sync.WaitGroup
+	for _, gn := range sorted {
+		n, res := gn, results[gn.name]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(res.done)
+
+			var inputs []File
+			for _, dep := range n.deps {
+				dres := results[dep]
+				<-dres.done
+				if dres.err != nil {
+					res.err = fmt.Errorf("%s: not run, as dependency %q failed", n.name, dep)
+					return
+				}
+				inputs = append(inputs, dres.fs.AsFiles()...)
+			}
+
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+			if err := ctx.Err(); err != nil {
+				res.err = err
+				return
+			}
+
+			if n.root != nil {
+				res.fs, res.err = n.root.GenerateFSContext(ctx, objs...)
+			} else {
+				res.fs, res.err = n.dependent.GenerateFSContext(ctx, inputs...)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Merge in topological order, so that the resulting FS and errors are
+	// deterministic.
+	gfs := NewFS()
+	var merr *multierror.Error
+	for _, n := range sorted {
+		res := results[n.name]
+		if res.err == nil {
+			res.err = gfs.Merge(res.fs)
+		}
+		if res.err != nil {
+			merr = multierror.Append(merr, res.err)
+		}
+	}
+	if merr.ErrorOrNil() != nil {
+		return nil, multierror.Flatten(merr)
+	}
+	return gfs, nil
+}
+
+func (g *JennyGraph[I]) Generate(objs ...I) (Files, error) {
+	return g.GenerateContext(context.Background(), objs...)
+}
+
+func (g *JennyGraph[I]) GenerateContext(ctx context.Context, objs ...I) (Files, error) {
+	gfs, err := g.GenerateFSContext(ctx, objs...)
+	if err != nil {
+		return nil, err
+	}
+	return gfs.AsFiles(), nil
+}
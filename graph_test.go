@@ -0,0 +1,199 @@
+package codejen
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// suffixJenny is a OneToOne that copies each input File to its path with
+// suffix appended, counting its calls.
+type suffixJenny struct {
+	suffix string
+	calls  *atomic.Int32
+}
+
+func (j suffixJenny) JennyName() string {
+	return "suffix"
+}
+
+func (j suffixJenny) Generate(f File) (*File, error) {
+	j.calls.Add(1)
+	return &File{
+		RelativePath: f.RelativePath + j.suffix,
+		Data:         f.Data,
+		From:         []NamedJenny{j},
+	}, nil
+}
+
+func TestJennyGraphOrder(t *testing.T) {
+	type dep struct {
+		name string
+		deps []string
+	}
+
+	tt := []struct {
+		name    string
+		roots   []string
+		deps    []dep
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "roots in order added",
+			roots: []string{"b", "a"},
+			want:  []string{"b", "a"},
+		},
+		{
+			name:  "dependents after dependencies",
+			roots: []string{"a"},
+			deps: []dep{
+				{name: "d", deps: []string{"c", "b"}},
+				{name: "c", deps: []string{"b"}},
+				{name: "b", deps: []string{"a"}},
+			},
+			want: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:  "independent branches interleaved by order added",
+			roots: []string{"a", "b"},
+			deps: []dep{
+				{name: "b2", deps: []string{"b"}},
+				{name: "a2", deps: []string{"a"}},
+			},
+			want: []string{"a", "b", "b2", "a2"},
+		},
+		{
+			name:  "cycle",
+			roots: []string{"a"},
+			deps: []dep{
+				{name: "b", deps: []string{"a", "c"}},
+				{name: "c", deps: []string{"b"}},
+			},
+			wantErr: "dependency cycle between jennies: b -> c -> b",
+		},
+		{
+			name:    "self dependency",
+			roots:   []string{"a"},
+			deps:    []dep{{name: "b", deps: []string{"b"}}},
+			wantErr: "dependency cycle between jennies: b -> b",
+		},
+		{
+			name:    "duplicate name",
+			roots:   []string{"a", "a"},
+			wantErr: "1 error occurred:\n\t* multiple jennies named \"a\"\n\n",
+		},
+		{
+			name:    "unknown dependency",
+			roots:   []string{"a"},
+			deps:    []dep{{name: "b", deps: []string{"x"}}},
+			wantErr: "1 error occurred:\n\t* jenny \"b\" depends on unknown jenny \"x\"\n\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// All jennies share a JennyName, and are told apart by node name.
+			g := NewJennyGraph[string]()
+			for _, name := range tc.roots {
+				g.Add(name, pathsJenny{name: "root", paths: []string{name}})
+			}
+			for _, d := range tc.deps {
+				g.AddDependent(d.name, suffixJenny{suffix: "." + d.name, calls: new(atomic.Int32)}, d.deps...)
+			}
+
+			sorted, err := g.sorted()
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, n := range sorted {
+				got = append(got, n.name)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected order (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJennyGraphGenerate(t *testing.T) {
+	errGen := errors.New("generate failed")
+	tt := []struct {
+		name    string
+		failA   bool
+		want    map[string]string
+		wantErr []string
+		// calls is the expected number of calls of the dependents of a.
+		calls int32
+	}{
+		{
+			name: "dependents receive outputs",
+			want: map[string]string{
+				"in.a":       "a",
+				"in.b":       "b",
+				"in.a.x":     "a",
+				"in.a.x.y":   "a",
+				"in.b.z":     "b",
+				"in.a.x.y.w": "a",
+				"in.b.z.v":   "b",
+			},
+			calls: 3,
+		},
+		{
+			name:  "dependents of failed jenny skipped",
+			failA: true,
+			wantErr: []string{
+				"A: generate failed",
+				`x: not run, as dependency "a" failed`,
+				`y: not run, as dependency "x" failed`,
+				`w: not run, as dependency "y" failed`,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := new(atomic.Int32)
+			a := pathsJenny{name: "A", paths: []string{"$.a"}, data: "a"}
+			if tc.failA {
+				a.err = errGen
+			}
+
+			g := NewJennyGraph[string]()
+			g.Add("a", a)
+			g.Add("b", pathsJenny{name: "B", paths: []string{"$.b"}, data: "b"})
+			g.AddDependent("x", suffixJenny{suffix: ".x", calls: calls}, "a")
+			g.AddDependent("y", suffixJenny{suffix: ".y", calls: calls}, "x")
+			g.AddDependent("z", suffixJenny{suffix: ".z", calls: new(atomic.Int32)}, "b")
+			// Depends on both branches, but only receives in.a.x.y, as the
+			// output of z is filtered out.
+			g.AddDependent("w", FilterOneToOne[File](suffixJenny{suffix: ".w", calls: calls}, func(f File) bool {
+				return f.RelativePath == "in.a.x.y"
+			}), "y", "z")
+			// Shares the JennyName of w.
+			g.AddDependent("v", FilterOneToOne[File](suffixJenny{suffix: ".v", calls: new(atomic.Int32)}, func(File) bool {
+				return true
+			}), "z")
+
+			jfs, err := g.GenerateFS("in")
+			if diff := cmp.Diff(tc.want, fsContents(jfs)); diff != "" {
+				t.Errorf("unexpected FS (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantErr, errStrings(err)); diff != "" {
+				t.Errorf("unexpected errors (-want +got):\n%s", diff)
+			}
+			if got := calls.Load(); got != tc.calls {
+				t.Errorf("expected %d calls of dependents of a, got %d", tc.calls, got)
+			}
+		})
+	}
+}
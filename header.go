@@ -0,0 +1,228 @@
+package codejen
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// commentStyle is the syntax for a single-line comment in some language.
+type commentStyle struct {
+	start, end string
+}
+
+var (
+	slashComment = commentStyle{start: "// "}
+	hashComment  = commentStyle{start: "# "}
+	dashComment  = commentStyle{start: "-- "}
+	semiComment  = commentStyle{start: "; "}
+	blockComment = commentStyle{start: "/* ", end: " */"}
+	htmlComment  = commentStyle{start: "<!-- ", end: " -->"}
+)
+
+// commentStylesByExt maps lowercase file extensions to the comment syntax of
+// the language they contain. Formats that lack comments, such as JSON, are
+// deliberately absent.
+var commentStylesByExt = map[string]commentStyle{
+	".go":        slashComment,
+	".ts":        slashComment,
+	".tsx":       slashComment,
+	".mts":       slashComment,
+	".cts":       slashComment,
+	".js":        slashComment,
+	".jsx":       slashComment,
+	".mjs":       slashComment,
+	".cjs":       slashComment,
+	".java":      slashComment,
+	".kt":        slashComment,
+	".kts":       slashComment,
+	".scala":     slashComment,
+	".groovy":    slashComment,
+	".swift":     slashComment,
+	".rs":        slashComment,
+	".c":         slashComment,
+	".h":         slashComment,
+	".cc":        slashComment,
+	".cpp":       slashComment,
+	".hpp":       slashComment,
+	".cs":        slashComment,
+	".dart":      slashComment,
+	".proto":     slashComment,
+	".cue":       slashComment,
+	".jsonnet":   slashComment,
+	".libsonnet": slashComment,
+	".scss":      slashComment,
+	".less":      slashComment,
+	".py":        hashComment,
+	".rb":        hashComment,
+	".pl":        hashComment,
+	".r":         hashComment,
+	".sh":        hashComment,
+	".bash":      hashComment,
+	".zsh":       hashComment,
+	".ps1":       hashComment,
+	".yaml":      hashComment,
+	".yml":       hashComment,
+	".toml":      hashComment,
+	".tf":        hashComment,
+	".hcl":       hashComment,
+	".bzl":       hashComment,
+	".star":      hashComment,
+	".mk":        hashComment,
+	".cfg":       hashComment,
+	".conf":      hashComment,
+	".env":       hashComment,
+	".sql":       dashComment,
+	".lua":       dashComment,
+	".hs":        dashComment,
+	".elm":       dashComment,
+	".ini":       semiComment,
+	".clj":       semiComment,
+	".el":        semiComment,
+	".lisp":      semiComment,
+	".css":       blockComment,
+	".md":        htmlComment,
+	".html":      htmlComment,
+	".htm":       htmlComment,
+	".xml":       htmlComment,
+	".svg":       htmlComment,
+	".vue":       htmlComment,
+}
+
+// commentStylesByName maps the base names of files conventionally lacking an
+// extension to the comment syntax of the language they contain.
+var commentStylesByName = map[string]commentStyle{
+	"Makefile":       hashComment,
+	"GNUmakefile":    hashComment,
+	"Dockerfile":     hashComment,
+	"Containerfile":  hashComment,
+	"BUILD":          hashComment,
+	"WORKSPACE":      hashComment,
+	"Gemfile":        hashComment,
+	"Rakefile":       hashComment,
+	"Jenkinsfile":    slashComment,
+	".gitignore":     hashComment,
+	".dockerignore":  hashComment,
+	".editorconfig":  hashComment,
+	".gitattributes": hashComment,
+}
+
+// headerComment returns the comment syntax for the file at the provided path,
+// and false if it is unknown or the file format has no comments.
+func headerComment(path string) (commentStyle, bool) {
+	base := filepath.Base(path)
+	if cs, has := commentStylesByName[base]; has {
+		return cs, true
+	}
+	cs, has := commentStylesByExt[strings.ToLower(filepath.Ext(base))]
+	return cs, has
+}
+
+var (
+	// dockerDirective matches a Dockerfile parser directive, such as
+	// "# syntax=docker/dockerfile:1". Directives must precede all other lines,
+	// including comments.
+	dockerDirective = regexp.MustCompile(`^#\s*[A-Za-z][A-Za-z0-9_-]*\s*=`)
+
+	// pythonEncoding matches a Python source encoding declaration, per PEP 263,
+	// which must be on the first or second line.
+	pythonEncoding = regexp.MustCompile(`^[ \t\f]*#.*?coding[:=][ \t]*[-_.a-zA-Z0-9]+`)
+
+	// rubyMagic matches a Ruby magic comment, which must be in the first
+	// comment section of the file.
+	rubyMagic = regexp.MustCompile(`^#\s*(?:-\*-\s*)?(?:en)?coding\s*[:=]|^#\s*(?:frozen_string_literal|warn_indent|shareable_constant_value)\s*:`)
+)
+
+// leadingLines returns the length of the prefix of data made up of lines that
+// must remain first in the file at path, ahead of any generated header: a
+// shebang (#!) line or XML declaration, Dockerfile parser directives, and
+// Python and Ruby encoding and magic comments.
+func leadingLines(path string, data []byte) int {
+	line := func(off int) []byte {
+		l := data[off:]
+		if i := bytes.IndexByte(l, '\n'); i >= 0 {
+			return l[:i+1]
+		}
+		return l
+	}
+
+	var n int
+	if l := line(0); bytes.HasPrefix(l, []byte("#!")) || bytes.HasPrefix(l, []byte("<?xml")) {
+		n = len(l)
+	}
+
+	base := filepath.Base(path)
+	switch {
+	case base == "Dockerfile" || base == "Containerfile":
+		for l := line(n); len(l) > 0 && dockerDirective.Match(l); l = line(n) {
+			n += len(l)
+		}
+	case strings.EqualFold(filepath.Ext(base), ".py"):
+		// The encoding declaration may follow any other line, so every line up
+		// to it is kept.
+		for i, off := 0, 0; i < 2 && off < len(data); i++ {
+			l := line(off)
+			off += len(l)
+			if pythonEncoding.Match(l) {
+				n = off
+			}
+		}
+	case strings.EqualFold(filepath.Ext(base), ".rb"):
+		for l := line(n); len(l) > 0 && rubyMagic.Match(l); l = line(n) {
+			n += len(l)
+		}
+	}
+	return n
+}
+
+// GeneratedHeader returns a [FileMapper] that prepends a comment to each File,
+// marking it as generated and naming the jennies that produced it:
+//
+//	// Code generated by Jenny1:Jenny2. DO NOT EDIT.
+//
+// The comment follows the convention recognized by Go tooling, linters, and
+// many code review tools. It is intended for use as a postprocessor, via
+// [JennyList.AddPostprocessors].
+//
+// The comment syntax is chosen based on the extension of the File's
+// RelativePath, or for files conventionally lacking an extension such as
+// Makefile or Dockerfile, its base name. Files in formats without comments,
+// such as JSON, and files of unrecognized types are returned unchanged.
+//
+// Lines that must come first in a file are kept ahead of the header: a shebang
+// (#!) line or XML declaration, Dockerfile parser directives such as
+// "# syntax=", Python encoding declarations along with any line preceding them,
+// and Ruby magic comments. Files
+// that already have a "Code generated" comment following such lines are
+// returned unchanged.
+func GeneratedHeader() FileMapper {
+	return func(f File) (File, error) {
+		cs, has := headerComment(f.RelativePath)
+		if !has {
+			return f, nil
+		}
+
+		n := leadingLines(f.RelativePath, f.Data)
+		first, rest := f.Data[:n], f.Data[n:]
+		if bytes.HasPrefix(rest, []byte(cs.start+"Code generated ")) {
+			return f, nil
+		}
+
+		var buf bytes.Buffer
+		buf.Write(first)
+		if len(first) > 0 && first[len(first)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, "%sCode generated by %s. DO NOT EDIT.%s\n", cs.start, f.FromString(), cs.end)
+		if len(rest) > 0 {
+			// A blank line prevents the header from being treated as a doc
+			// comment, e.g. on a Go package clause.
+			buf.WriteByte('\n')
+			buf.Write(rest)
+		}
+		f.Data = buf.Bytes()
+		return f, nil
+	}
+}
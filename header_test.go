@@ -0,0 +1,154 @@
+package codejen
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGeneratedHeader(t *testing.T) {
+	const hdr = "Code generated by A:B. DO NOT EDIT."
+
+	tt := []struct {
+		name string
+		path string
+		data string
+		want string
+	}{
+		{
+			name: "go",
+			path: "x/x.go",
+			data: "package x\n",
+			want: "// " + hdr + "\n\npackage x\n",
+		},
+		{
+			name: "empty",
+			path: "x.go",
+			data: "",
+			want: "// " + hdr + "\n",
+		},
+		{
+			name: "shebang",
+			path: "run.sh",
+			data: "#!/bin/sh\necho hi\n",
+			want: "#!/bin/sh\n# " + hdr + "\n\necho hi\n",
+		},
+		{
+			name: "shebang without newline",
+			path: "run.sh",
+			data: "#!/bin/sh",
+			want: "#!/bin/sh\n# " + hdr + "\n",
+		},
+		{
+			name: "xml prolog",
+			path: "x.xml",
+			data: "<?xml version=\"1.0\"?>\n<x/>\n",
+			want: "<?xml version=\"1.0\"?>\n<!-- " + hdr + " -->\n\n<x/>\n",
+		},
+		{
+			name: "css block comment",
+			path: "x.CSS",
+			data: "a {}\n",
+			want: "/* " + hdr + " */\n\na {}\n",
+		},
+		{
+			name: "dockerfile directives",
+			path: "build/Dockerfile",
+			data: "# syntax=docker/dockerfile:1\n# escape=`\nFROM scratch\n",
+			want: "# syntax=docker/dockerfile:1\n# escape=`\n# " + hdr + "\n\nFROM scratch\n",
+		},
+		{
+			name: "dockerfile comment is not a directive",
+			path: "Dockerfile",
+			data: "# base image\nFROM scratch\n",
+			want: "# " + hdr + "\n\n# base image\nFROM scratch\n",
+		},
+		{
+			name: "python encoding on first line",
+			path: "x.py",
+			data: "# -*- coding: utf-8 -*-\nx = 1\n",
+			want: "# -*- coding: utf-8 -*-\n# " + hdr + "\n\nx = 1\n",
+		},
+		{
+			name: "python encoding after shebang",
+			path: "x.py",
+			data: "#!/usr/bin/env python\n# coding: latin-1\nx = 1\n",
+			want: "#!/usr/bin/env python\n# coding: latin-1\n# " + hdr + "\n\nx = 1\n",
+		},
+		{
+			name: "python encoding after comment",
+			path: "x.py",
+			data: "# comment\n# coding: utf-8\nx = 1\n",
+			want: "# comment\n# coding: utf-8\n# " + hdr + "\n\nx = 1\n",
+		},
+		{
+			name: "python encoding on third line",
+			path: "x.py",
+			data: "# one\n# two\n# coding: utf-8\n",
+			want: "# " + hdr + "\n\n# one\n# two\n# coding: utf-8\n",
+		},
+		{
+			name: "ruby magic comments",
+			path: "x.rb",
+			data: "#!/usr/bin/env ruby\n# frozen_string_literal: true\n# encoding: utf-8\nputs 1\n",
+			want: "#!/usr/bin/env ruby\n# frozen_string_literal: true\n# encoding: utf-8\n# " + hdr + "\n\nputs 1\n",
+		},
+		{
+			name: "makefile by name",
+			path: "Makefile",
+			data: "all:\n",
+			want: "# " + hdr + "\n\nall:\n",
+		},
+		{
+			name: "json unchanged",
+			path: "x.json",
+			data: "{}\n",
+			want: "{}\n",
+		},
+		{
+			name: "unknown extension unchanged",
+			path: "x.unknown",
+			data: "x\n",
+			want: "x\n",
+		},
+		{
+			name: "existing header unchanged",
+			path: "x.go",
+			data: "// Code generated by other. DO NOT EDIT.\n\npackage x\n",
+			want: "// Code generated by other. DO NOT EDIT.\n\npackage x\n",
+		},
+		{
+			name: "existing header after shebang unchanged",
+			path: "run.sh",
+			data: "#!/bin/sh\n# Code generated by other. DO NOT EDIT.\n",
+			want: "#!/bin/sh\n# Code generated by other. DO NOT EDIT.\n",
+		},
+	}
+
+	header := GeneratedHeader()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f := File{
+				RelativePath: tc.path,
+				Data:         []byte(tc.data),
+				From:         []NamedJenny{testJenny("A"), testJenny("B")},
+			}
+			got, err := header(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, string(got.Data)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+
+			// Applying the header again has no effect.
+			again, err := header(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, string(again.Data)); diff != "" {
+				t.Errorf("not idempotent (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
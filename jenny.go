@@ -1,5 +1,11 @@
 package codejen
 
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
 // A Jenny is a single codejen code generator.
 //
 // Each Jenny works with exactly one type of input to its code generation, as
@@ -23,6 +29,11 @@ package codejen
 //   - [ManyToMany]: many [Input]s in, many [File]s out
 //
 // All jennies will follow exactly one of these four interfaces.
+//
+// Each of the four also has a context-aware variant - [OneToOneContext],
+// [OneToManyContext], [ManyToOneContext] and [ManyToManyContext] - whose
+// GenerateContext method accepts a [context.Context]. [JennyList] prefers
+// GenerateContext for jennies implementing both.
 type Jenny[I Input] interface {
 	// JennyName returns the name of the generator.
 	JennyName() string
@@ -38,6 +49,49 @@ type NamedJenny interface {
 	JennyName() string
 }
 
+// ErrSkipInput may be returned from the input conversion func passed to an
+// error-returning adapter, such as [AdaptOneToOneErr] or [AdaptOneToOneContext],
+// to indicate that the input should be skipped. A one-input jenny is then not
+// called for that input, and a many-input jenny is called without it.
+var ErrSkipInput = errors.New("skip input")
+
+// adaptInput converts a single input with fn. skip is true if fn returned
+// [ErrSkipInput].
+func adaptInput[InI, OutI Input](ctx context.Context, p OutI, fn func(context.Context, OutI) (InI, error)) (q InI, skip bool, err error) {
+	q, err = fn(ctx, p)
+	if errors.Is(err, ErrSkipInput) {
+		return q, true, nil
+	}
+	if err != nil {
+		return q, false, fmt.Errorf("failed to adapt input: %w", err)
+	}
+	return q, false, nil
+}
+
+// adaptInputs converts each input with fn, omitting those for which fn
+// returned [ErrSkipInput].
+func adaptInputs[InI, OutI Input](ctx context.Context, ps []OutI, fn func(context.Context, OutI) (InI, error)) ([]InI, error) {
+	qs := make([]InI, 0, len(ps))
+	for i, p := range ps {
+		q, err := fn(ctx, p)
+		if errors.Is(err, ErrSkipInput) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to adapt input %d: %w", i, err)
+		}
+		qs = append(qs, q)
+	}
+	return qs, nil
+}
+
+// ignoreContext converts an input conversion func to the context-aware form.
+func ignoreContext[InI, OutI Input](fn func(OutI) (InI, error)) func(context.Context, OutI) (InI, error) {
+	return func(_ context.Context, p OutI) (InI, error) {
+		return fn(p)
+	}
+}
+
 // Input is used in generic type parameters solely to indicate to
 // human eyes that that type parameter is used to govern the type passed as input to
 // a jenny's Generate method.
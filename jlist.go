@@ -1,10 +1,14 @@
 package codejen
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/hashicorp/go-multierror"
+	"golang.org/x/sync/errgroup"
 )
 
 type jnode struct {
@@ -21,7 +25,10 @@ func JennyListWithNamer[Input any](namer func(t Input) string) *JennyList[Input]
 	}
 }
 
-var _ ManyToMany[any] = &JennyList[any]{}
+var (
+	_ ManyToMany[any]        = &JennyList[any]{}
+	_ ManyToManyContext[any] = &JennyList[any]{}
+)
 
 // JennyList is an ordered collection of jennies. JennyList itself implements
 // [ManyToMany], and when called, will construct an [FS] by calling each of its
@@ -34,7 +41,8 @@ var _ ManyToMany[any] = &JennyList[any]{}
 // The File outputs of all member jennies in a JennyList exist in the same
 // relative path namespace. JennyList does not modify emitted paths. Path
 // uniqueness (per [Files.Validate]) is internally enforced across the aggregate
-// set of Files.
+// set of Files, unless a [ConflictResolver] is set with
+// [JennyList.SetConflictResolver].
 //
 // JennyList's Input type parameter is used to enforce that every Jenny in the
 // JennyList takes the same type parameter.
@@ -49,6 +57,20 @@ type JennyList[Input any] struct {
 
 	// inputnamer, if non-nil, gives a name to an input.
 	inputnamer func(t Input) string
+
+	// concurrency is the maximum number of concurrent jenny calls. Values less
+	// than 2 run jennies serially.
+	concurrency int
+
+	// resolver, if non-nil, combines Files generated at the same path.
+	resolver ConflictResolver
+
+	// errpolicy determines how errors from jennies are handled.
+	errpolicy ErrorPolicy
+
+	// lineage, if true, appends the From of File inputs to the From of the
+	// Files generated from them, per lineageOf. Set by newFileJennyList.
+	lineage bool
 }
 
 func (jl *JennyList[Input]) last() *jnode {
@@ -63,17 +85,178 @@ func (jl *JennyList[Input]) JennyName() string {
 	return fmt.Sprintf("JennyList[%T]", new(Input))
 }
 
-func (jl *JennyList[Input]) wrapinerr(in Input, err error) error {
+// Phase identifies the step of [JennyList.GenerateFS] in which a [JennyError]
+// occurred.
+type Phase string
+
+const (
+	// PhaseGenerate is the call of the jenny itself.
+	PhaseGenerate Phase = "generate"
+
+	// PhaseValidate is the validation of the Files returned from the jenny, per
+	// [Files.Validate].
+	PhaseValidate Phase = "validate"
+
+	// PhasePostprocess is the application of postprocessors added with
+	// [JennyList.AddPostprocessors] to the Files returned from the jenny.
+	PhasePostprocess Phase = "postprocess"
+
+	// PhaseAdd is the addition of the Files returned from the jenny to the
+	// output FS, which fails if their paths conflict with those of Files from
+	// another jenny.
+	PhaseAdd Phase = "add"
+)
+
+// JennyError is the type of all errors returned from [JennyList.GenerateFS]
+// that arise from a particular jenny. Use [errors.As] to retrieve it.
+type JennyError struct {
+	// Jenny is the jenny that failed.
+	Jenny NamedJenny
+
+	// Stack is the stack of jennies responsible for the error, outermost
+	// first. For PhaseGenerate, if the jenny is itself a JennyList or other
+	// composite jenny that returned a JennyError, Stack includes the stack of
+	// that error. For PhasePostprocess, Stack is the [File.From] of the File
+	// being postprocessed.
+	Stack []NamedJenny
+
+	// Input is the input the jenny was called with, for [OneToOne] and
+	// [OneToMany] jennies. It is nil for jennies that take many inputs.
+	Input any
+
+	// InputName is the name of Input, as given by the namer passed to
+	// [JennyListWithNamer]. It is empty if Input is nil or the JennyList has
+	// no namer.
+	InputName string
+
+	// Phase is the step of generation in which the error occurred.
+	Phase Phase
+
+	// Path is the RelativePath of the File being postprocessed, for
+	// PhasePostprocess.
+	Path string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *JennyError) Error() string {
+	var msg string
+	switch e.Phase {
+	case PhaseGenerate:
+		msg = fmt.Sprintf("%s: %s", e.Jenny.JennyName(), e.Err)
+	case PhaseValidate:
+		msg = fmt.Sprintf("%s returned invalid Files: %s", e.Jenny.JennyName(), e.Err)
+	case PhasePostprocess:
+		msg = fmt.Sprintf("postprocessing of %s from %s failed: %s", e.Path, jennystack(e.Stack), e.Err)
+	default:
+		msg = e.Err.Error()
+	}
+	if e.InputName != "" {
+		msg = fmt.Sprintf("%s for input %q", msg, e.InputName)
+	}
+	return msg
+}
+
+func (e *JennyError) Unwrap() error {
+	return e.Err
+}
+
+// withInput sets the input of all JennyErrors in err, and returns it.
+func (jl *JennyList[Input]) withInput(in Input, err error) error {
+	var jerrs []*JennyError
+	if merr, ok := err.(*multierror.Error); ok {
+		for _, e := range merr.Errors {
+			if jerr, ok := e.(*JennyError); ok {
+				jerrs = append(jerrs, jerr)
+			}
+		}
+	} else if jerr, ok := err.(*JennyError); ok {
+		jerrs = append(jerrs, jerr)
+	}
+
+	for _, jerr := range jerrs {
+		jerr.Input = in
+		if jl.inputnamer != nil {
+			jerr.InputName = jl.inputnamer(in)
+		}
+	}
+	return err
+}
+
+// jcall is a single invocation of a jenny by GenerateFS.
+type jcall[Input any] struct {
+	// j is the jenny being called.
+	j Jenny[Input]
+
+	// in is the input for one-input jennies, nil for many-input jennies.
+	in *Input
+
+	// run calls the jenny, returning its validated output, before
+	// postprocessing.
+	run func(context.Context) (Files, error)
+
+	// raw is the output of run, and out the same Files after postprocessing.
+	raw Files
+	out Files
+	err error
+
+	// ran is true if the call was made. Calls may be skipped if ctx is done,
+	// or another call failed under FailFast.
+	ran bool
+}
+
+// addErrs converts errors returned from adding the outputs of j to an FS into
+// JennyErrors, one per conflicting File.
+func addErrs(j NamedJenny, err error) error {
 	if err == nil {
 		return nil
 	}
-	if jl.inputnamer == nil {
-		return err
+	merr, ok := err.(*multierror.Error)
+	if !ok {
+		return &JennyError{Jenny: j, Stack: []NamedJenny{j}, Phase: PhaseAdd, Err: err}
 	}
-	return fmt.Errorf("%w for input %q", err, jl.inputnamer(in))
+	result := new(multierror.Error)
+	for _, e := range merr.Errors {
+		result = multierror.Append(result, &JennyError{Jenny: j, Stack: []NamedJenny{j}, Phase: PhaseAdd, Err: e})
+	}
+	return result
 }
 
+// ErrorPolicy determines how a [JennyList] handles errors returned from its
+// jennies.
+type ErrorPolicy int
+
+const (
+	// CollectErrors calls every jenny, and if any fail, returns the errors from
+	// all of them and no FS. This is the default.
+	CollectErrors ErrorPolicy = iota
+
+	// FailFast stops calling jennies after the first error, and returns only
+	// that error. In concurrent mode, calls already scheduled are allowed to
+	// complete, and the first error in call order is returned.
+	//
+	// Path conflicts between the outputs of different jennies are only
+	// detected once all jennies were called, and so do not stop further calls.
+	// As with other errors, only the first error in call order is returned.
+	FailFast
+
+	// PartialResults calls every jenny, and returns the errors from all that
+	// failed along with an FS containing the outputs of all that succeeded.
+	PartialResults
+)
+
+// GenerateFS calls each jenny in the JennyList with the provided inputs, and
+// returns an FS containing all of their outputs. Errors are handled according
+// to the JennyList's [ErrorPolicy].
 func (jl *JennyList[Input]) GenerateFS(objs ...Input) (*FS, error) {
+	return jl.GenerateFSContext(context.Background(), objs...)
+}
+
+// GenerateFSContext is like [JennyList.GenerateFS], but passes ctx to all
+// context-aware jennies. If ctx is done, no further jennies are called, and
+// ctx.Err() is returned.
+func (jl *JennyList[Input]) GenerateFSContext(ctx context.Context, objs ...Input) (*FS, error) {
 	jl.mut.RLock()
 	defer jl.mut.RUnlock()
 
@@ -81,87 +264,328 @@ func (jl *JennyList[Input]) GenerateFS(objs ...Input) (*FS, error) {
 		return nil, nil
 	}
 
+	calls := jl.calls(objs)
+	jl.runCalls(ctx, calls)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Outputs are added in call order, regardless of the order in which the calls
+	// completed, so that the resulting FS and errors are deterministic.
 	jfs := NewFS()
+	raw := make(map[string]rawFile)
+	result := new(multierror.Error)
+	for _, c := range calls {
+		if !c.ran {
+			continue
+		}
+		err := c.err
+		if err == nil {
+			err = jl.add(jfs, raw, c)
+		}
+		if c.in != nil {
+			err = jl.withInput(*c.in, err)
+		}
+		if err != nil {
+			if jl.errpolicy == FailFast {
+				return nil, err
+			}
+			result = multierror.Append(result, err)
+		}
+	}
+
+	if result.ErrorOrNil() != nil {
+		if jl.errpolicy == PartialResults {
+			return jfs, multierror.Flatten(result)
+		}
+		return nil, multierror.Flatten(result)
+	}
+
+	return jfs, nil
+}
+
+// rawFile is a File added to an FS by GenerateFS, before postprocessing, and the
+// path at which it was added after postprocessing.
+type rawFile struct {
+	f    File
+	path string
+}
+
+// add adds the outputs of c to jfs, all or nothing. raw holds the Files
+// previously added, before postprocessing, by path.
+//
+// If a ConflictResolver is set, an output at the same path as a previous File is
+// resolved against that File before either is postprocessed, as postprocessors
+// may legitimately make otherwise identical Files differ, such as by naming the
+// jenny that generated them. The resolved File is then postprocessed, and
+// replaces the previous File in jfs.
+func (jl *JennyList[Input]) add(jfs *FS, raw map[string]rawFile, c *jcall[Input]) error {
+	result := new(multierror.Error)
+	add := make(Files, 0, len(c.out))
+	added := make(map[string]rawFile, len(c.raw))
+	var replaced []string
+	for i, f := range c.raw {
+		prev, has := raw[f.RelativePath]
+		if !has || jl.resolver == nil {
+			add = append(add, c.out[i])
+			added[f.RelativePath] = rawFile{f: f, path: c.out[i].RelativePath}
+			continue
+		}
 
-	manyout := func(j Jenny[Input], err error, fl ...File) error {
+		rf, err := resolveConflict(jl.resolver, prev.f, f)
 		if err != nil {
-			return fmt.Errorf("%s: %w", j.JennyName(), err)
+			result = multierror.Append(result, addErrs(c.j, err))
+			continue
 		}
+		pf, err := jl.postprocess(c.j, rf)
+		if err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+		add = append(add, pf)
+		added[f.RelativePath] = rawFile{f: rf, path: pf.RelativePath}
+		replaced = append(replaced, prev.path)
+	}
+	if result.ErrorOrNil() != nil {
+		return result
+	}
 
-		if err = Files(fl).Validate(); err != nil {
-			// This is unreachable in the case where there was a single File output, so plural is fine
-			return fmt.Errorf("%s returned invalid Files: %w", j.JennyName(), err)
+	// Replaced Files are removed so that their replacements may be added, and
+	// restored if adding fails.
+	removed := make(map[string]*mapFile, len(replaced))
+	for _, p := range replaced {
+		removed[p] = jfs.mapFS[p]
+		delete(jfs.mapFS, p)
+	}
+	if err := jfs.addValidated(add...); err != nil {
+		for p, mf := range removed {
+			jfs.mapFS[p] = mf
 		}
+		return addErrs(c.j, err)
+	}
+	for p, rf := range added {
+		raw[p] = rf
+	}
+	return nil
+}
 
-		// postprocessing
-		for i, f := range fl {
-			for _, post := range jl.post {
-				of, err := post(f)
-				if err != nil {
-					return fmt.Errorf("postprocessing of %s from %s failed: %w", f.RelativePath, jennystack(f.From), err)
-				}
-				f = of
+// postprocess runs all postprocessors, in order, on a File output by j.
+func (jl *JennyList[Input]) postprocess(j NamedJenny, f File) (File, error) {
+	for _, post := range jl.post {
+		of, err := post(f)
+		if err != nil {
+			return File{}, &JennyError{
+				Jenny: j,
+				Stack: f.From,
+				Phase: PhasePostprocess,
+				Path:  f.RelativePath,
+				Err:   err,
 			}
-			fl[i] = f
 		}
-		return jfs.addValidated(fl...)
+		f = of
 	}
-	oneout := func(j Jenny[Input], f *File, err error) error {
+	return f, nil
+}
+
+// calls returns the set of jenny invocations needed to generate from the
+// provided inputs, in order. Must be called with the read lock held.
+func (jl *JennyList[Input]) calls(objs []Input) []*jcall[Input] {
+	manyout := func(j Jenny[Input], lineage []NamedJenny, err error, fl ...File) (Files, error) {
+		if err != nil {
+			jerr := &JennyError{
+				Jenny: j,
+				Stack: []NamedJenny{j},
+				Phase: PhaseGenerate,
+				Err:   err,
+			}
+			var inner *JennyError
+			if errors.As(err, &inner) {
+				jerr.Stack = append(jerr.Stack, inner.Stack...)
+			}
+			return nil, jerr
+		}
+
+		if fl, err = Files(fl).Resolve(jl.resolver); err != nil {
+			// This is unreachable in the case where there was a single File output, so plural is fine
+			return nil, &JennyError{
+				Jenny: j,
+				Stack: []NamedJenny{j},
+				Phase: PhaseValidate,
+				Err:   err,
+			}
+		}
+
+		appendFrom(fl, lineage)
+		return fl, nil
+	}
+	oneout := func(j Jenny[Input], lineage []NamedJenny, f *File, err error) (Files, error) {
 		// errs and empty file case are handled by manyout with a zero-len variadic arg
 		if err != nil || f == nil || !f.Exists() {
-			return manyout(j, err)
+			return manyout(j, lineage, err)
 		}
-		return manyout(j, err, *f)
+		return manyout(j, lineage, err, *f)
+	}
+	chain := func(ins ...Input) []NamedJenny {
+		if !jl.lineage {
+			return nil
+		}
+		return lineageOf(ins...)
 	}
 
-	result := new(multierror.Error)
-	jn := jl.first
-	for jn != nil {
-		var handlerr error
+	var calls []*jcall[Input]
+	for jn := jl.first; jn != nil; jn = jn.next {
 		switch jenny := jn.j.(type) {
+		case OneToOneContext[Input]:
+			for i := range objs {
+				obj := &objs[i]
+				calls = append(calls, &jcall[Input]{
+					j:  jenny,
+					in: obj,
+					run: func(ctx context.Context) (Files, error) {
+						f, err := jenny.GenerateContext(ctx, *obj)
+						return oneout(jenny, chain(*obj), f, err)
+					},
+				})
+			}
+		case OneToManyContext[Input]:
+			for i := range objs {
+				obj := &objs[i]
+				calls = append(calls, &jcall[Input]{
+					j:  jenny,
+					in: obj,
+					run: func(ctx context.Context) (Files, error) {
+						fl, err := jenny.GenerateContext(ctx, *obj)
+						return manyout(jenny, chain(*obj), err, fl...)
+					},
+				})
+			}
+		case ManyToOneContext[Input]:
+			calls = append(calls, &jcall[Input]{
+				j: jenny,
+				run: func(ctx context.Context) (Files, error) {
+					f, err := jenny.GenerateContext(ctx, objs...)
+					return oneout(jenny, chain(objs...), f, err)
+				},
+			})
+		case ManyToManyContext[Input]:
+			calls = append(calls, &jcall[Input]{
+				j: jenny,
+				run: func(ctx context.Context) (Files, error) {
+					fl, err := jenny.GenerateContext(ctx, objs...)
+					return manyout(jenny, chain(objs...), err, fl...)
+				},
+			})
 		case OneToOne[Input]:
-			for _, obj := range objs {
-				f, err := jenny.Generate(obj)
-				if procerr := jl.wrapinerr(obj, oneout(jenny, f, err)); procerr != nil {
-					result = multierror.Append(result, procerr)
-				}
+			for i := range objs {
+				obj := &objs[i]
+				calls = append(calls, &jcall[Input]{
+					j:  jenny,
+					in: obj,
+					run: func(context.Context) (Files, error) {
+						f, err := jenny.Generate(*obj)
+						return oneout(jenny, chain(*obj), f, err)
+					},
+				})
 			}
 		case OneToMany[Input]:
-			for _, obj := range objs {
-				fl, err := jenny.Generate(obj)
-				if procerr := jl.wrapinerr(obj, manyout(jenny, err, fl...)); procerr != nil {
-					result = multierror.Append(result, procerr)
-				}
+			for i := range objs {
+				obj := &objs[i]
+				calls = append(calls, &jcall[Input]{
+					j:  jenny,
+					in: obj,
+					run: func(context.Context) (Files, error) {
+						fl, err := jenny.Generate(*obj)
+						return manyout(jenny, chain(*obj), err, fl...)
+					},
+				})
 			}
 		case ManyToOne[Input]:
-			f, err := jenny.Generate(objs...)
-			handlerr = oneout(jenny, f, err)
+			calls = append(calls, &jcall[Input]{
+				j: jenny,
+				run: func(context.Context) (Files, error) {
+					f, err := jenny.Generate(objs...)
+					return oneout(jenny, chain(objs...), f, err)
+				},
+			})
 		case ManyToMany[Input]:
-			fl, err := jenny.Generate(objs...)
-			handlerr = manyout(jenny, err, fl...)
+			calls = append(calls, &jcall[Input]{
+				j: jenny,
+				run: func(context.Context) (Files, error) {
+					fl, err := jenny.Generate(objs...)
+					return manyout(jenny, chain(objs...), err, fl...)
+				},
+			})
 		default:
 			panic("unreachable")
 		}
+	}
+	return calls
+}
 
-		if handlerr != nil {
-			result = multierror.Append(result, handlerr)
+// runCalls runs all the provided calls, concurrently if so configured. Calls
+// are skipped once ctx is done, or once any call fails under FailFast.
+func (jl *JennyList[Input]) runCalls(ctx context.Context, calls []*jcall[Input]) {
+	var failed atomic.Bool
+	run := func(c *jcall[Input]) {
+		c.raw, c.err = c.run(ctx)
+		if c.err == nil {
+			c.out = make(Files, len(c.raw))
+			for i, f := range c.raw {
+				if c.out[i], c.err = jl.postprocess(c.j, f); c.err != nil {
+					c.out = nil
+					break
+				}
+			}
 		}
-		jn = jn.next
+		c.ran = true
+		if c.err != nil && jl.errpolicy == FailFast {
+			failed.Store(true)
+		}
+	}
+	stop := func() bool {
+		return ctx.Err() != nil || failed.Load()
 	}
 
-	if result.ErrorOrNil() != nil {
-		return nil, multierror.Flatten(result)
+	if jl.concurrency <= 1 {
+		for _, c := range calls {
+			if stop() {
+				return
+			}
+			run(c)
+		}
+		return
 	}
 
-	return jfs, nil
+	var g errgroup.Group
+	g.SetLimit(jl.concurrency)
+	for _, call := range calls {
+		if stop() {
+			break
+		}
+		c := call
+		g.Go(func() error {
+			// Calls already scheduled are allowed to run under FailFast, so that
+			// every call before the first failing one in call order is made.
+			if ctx.Err() == nil {
+				run(c)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
 }
 
 func (jl *JennyList[Input]) Generate(objs ...Input) (Files, error) {
-	jfs, err := jl.GenerateFS(objs...)
-	if err != nil {
+	return jl.GenerateContext(context.Background(), objs...)
+}
+
+func (jl *JennyList[Input]) GenerateContext(ctx context.Context, objs ...Input) (Files, error) {
+	jfs, err := jl.GenerateFSContext(ctx, objs...)
+	if jfs == nil {
 		return nil, err
 	}
-	return jfs.AsFiles(), nil
+	// jfs may be non-nil along with err under PartialResults.
+	return jfs.AsFiles(), err
 }
 
 func (jl *JennyList[Input]) append(n ...*jnode) {
@@ -193,13 +617,14 @@ func tojnode[J NamedJenny](jennies ...J) []*jnode {
 // called in the order they were appended.
 //
 // All provided jennies must also implement one of [OneToOne], [OneToMany],
-// [ManyToOne], [ManyToMany], or this method will panic. For proper type safety,
-// use the Append* methods.
+// [ManyToOne], [ManyToMany], or their context-aware variants, or this method
+// will panic. For proper type safety, use the Append* methods.
 func (jl *JennyList[Input]) Append(jennies ...Jenny[Input]) {
 	nlist := make([]*jnode, len(jennies))
 	for i, j := range jennies {
 		switch j.(type) {
-		case OneToOne[Input], OneToMany[Input], ManyToOne[Input], ManyToMany[Input]:
+		case OneToOne[Input], OneToMany[Input], ManyToOne[Input], ManyToMany[Input],
+			OneToOneContext[Input], OneToManyContext[Input], ManyToOneContext[Input], ManyToManyContext[Input]:
 			nlist[i] = &jnode{
 				j: j,
 			}
@@ -210,8 +635,9 @@ func (jl *JennyList[Input]) Append(jennies ...Jenny[Input]) {
 	codejen.OneToMany[%T]
 	codejen.ManyToOne[%T]
 	codejen.ManyToMany[%T]
+or one of their context-aware variants, e.g. codejen.OneToOneContext[%T]
 `
-			panic(fmt.Sprintf(errtxt, j, intyp, intyp, intyp, intyp))
+			panic(fmt.Sprintf(errtxt, j, intyp, intyp, intyp, intyp, intyp))
 		}
 	}
 	jl.append(nlist...)
@@ -237,6 +663,30 @@ func (jl *JennyList[Input]) AppendManyToMany(jennies ...ManyToMany[Input]) {
 	jl.append(tojnode(jennies...)...)
 }
 
+// AppendOneToOneContext is like [JennyList.Append], but typesafe for
+// OneToOneContext jennies.
+func (jl *JennyList[Input]) AppendOneToOneContext(jennies ...OneToOneContext[Input]) {
+	jl.append(tojnode(jennies...)...)
+}
+
+// AppendManyToOneContext is like [JennyList.Append], but typesafe for
+// ManyToOneContext jennies.
+func (jl *JennyList[Input]) AppendManyToOneContext(jennies ...ManyToOneContext[Input]) {
+	jl.append(tojnode(jennies...)...)
+}
+
+// AppendOneToManyContext is like [JennyList.Append], but typesafe for
+// OneToManyContext jennies.
+func (jl *JennyList[Input]) AppendOneToManyContext(jennies ...OneToManyContext[Input]) {
+	jl.append(tojnode(jennies...)...)
+}
+
+// AppendManyToManyContext is like [JennyList.Append], but typesafe for
+// ManyToManyContext jennies.
+func (jl *JennyList[Input]) AppendManyToManyContext(jennies ...ManyToManyContext[Input]) {
+	jl.append(tojnode(jennies...)...)
+}
+
 // AddPostprocessors appends a slice of FileMapper to its internal list of
 // postprocessors.
 //
@@ -246,3 +696,39 @@ func (jl *JennyList[Input]) AddPostprocessors(fn ...FileMapper) {
 	jl.post = append(jl.post, fn...)
 	jl.mut.Unlock()
 }
+
+// SetConcurrency sets the maximum number of jenny calls made concurrently by
+// [JennyList.GenerateFS]. By default, jennies are called serially.
+//
+// In concurrent mode, each jenny, and each call of a [OneToOne] or [OneToMany]
+// jenny on an individual input, may run in parallel with any other. Jennies
+// and postprocessors must therefore be safe for concurrent use. The resulting
+// FS and errors are the same as in serial mode.
+func (jl *JennyList[Input]) SetConcurrency(n int) {
+	jl.mut.Lock()
+	jl.concurrency = n
+	jl.mut.Unlock()
+}
+
+// SetErrorPolicy sets how [JennyList.GenerateFS] handles errors returned from
+// jennies. The default is [CollectErrors].
+func (jl *JennyList[Input]) SetErrorPolicy(p ErrorPolicy) {
+	jl.mut.Lock()
+	jl.errpolicy = p
+	jl.mut.Unlock()
+}
+
+// SetConflictResolver sets the [ConflictResolver] used to combine Files
+// generated at the same path, whether by the same jenny or by different
+// jennies. Files are resolved in call order, before postprocessing, so that
+// resolvers such as [IdenticalContent] compare Files as generated rather than
+// as modified by postprocessors such as [GeneratedHeader]. The resolved File is
+// then postprocessed. By default, conflicts are an error.
+//
+// The resolver applies only within the JennyList; it is not carried into the
+// FS returned from [JennyList.GenerateFS].
+func (jl *JennyList[Input]) SetConflictResolver(r ConflictResolver) {
+	jl.mut.Lock()
+	jl.resolver = r
+	jl.mut.Unlock()
+}
@@ -0,0 +1,307 @@
+package codejen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-multierror"
+)
+
+// pathsJenny is a OneToMany that generates a File at each of paths for each
+// input, with "$" in paths and data replaced by the input.
+type pathsJenny struct {
+	name  string
+	paths []string
+	data  string
+
+	// err is returned for the input failOn, or for all inputs if failOn is
+	// empty.
+	err    error
+	failOn string
+}
+
+func (j pathsJenny) JennyName() string {
+	return j.name
+}
+
+func (j pathsJenny) Generate(in string) (Files, error) {
+	if j.err != nil && (j.failOn == "" || j.failOn == in) {
+		return nil, j.err
+	}
+	var fl Files
+	for _, p := range j.paths {
+		fl = append(fl, File{
+			RelativePath: strings.ReplaceAll(p, "$", in),
+			Data:         []byte(strings.ReplaceAll(j.data, "$", in)),
+			From:         []NamedJenny{j},
+		})
+	}
+	return fl, nil
+}
+
+// fsContents returns the contents of every File in jfs by path, or nil if jfs
+// is nil.
+func fsContents(jfs *FS) map[string]string {
+	if jfs == nil {
+		return nil
+	}
+	m := make(map[string]string)
+	for _, f := range jfs.AsFiles() {
+		m[f.RelativePath] = string(f.Data)
+	}
+	return m
+}
+
+// errStrings returns the messages of each error wrapped in err, in order.
+func errStrings(err error) []string {
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		errs = merr.Errors
+	}
+	var strs []string
+	for _, e := range errs {
+		strs = append(strs, e.Error())
+	}
+	return strs
+}
+
+func TestJennyListConflicts(t *testing.T) {
+	tt := []struct {
+		name     string
+		jennies  []OneToMany[string]
+		resolver ConflictResolver
+		want     map[string]string
+		wantErrs []string
+	}{
+		{
+			name: "failed add contributes nothing",
+			jennies: []OneToMany[string]{
+				pathsJenny{name: "A", paths: []string{"shared"}, data: "a"},
+				pathsJenny{name: "B", paths: []string{"$.b", "shared"}, data: "b"},
+			},
+			want: map[string]string{
+				"shared": "// Code generated by A. DO NOT EDIT.\n\na",
+			},
+			wantErrs: []string{`cannot create shared for jenny "B", path already created by jenny "A" for input "in"`},
+		},
+		{
+			name: "failed resolution contributes nothing",
+			jennies: []OneToMany[string]{
+				pathsJenny{name: "A", paths: []string{"shared"}, data: "a"},
+				pathsJenny{name: "B", paths: []string{"$.b", "shared"}, data: "b"},
+			},
+			resolver: IdenticalContent,
+			want: map[string]string{
+				"shared": "// Code generated by A. DO NOT EDIT.\n\na",
+			},
+			wantErrs: []string{`cannot create shared for jenny "B", path already created by jenny "A": contents differ for input "in"`},
+		},
+		{
+			name: "resolved before postprocessing",
+			jennies: []OneToMany[string]{
+				pathsJenny{name: "A", paths: []string{"shared"}, data: "x"},
+				pathsJenny{name: "B", paths: []string{"$.b", "shared"}, data: "x"},
+			},
+			resolver: IdenticalContent,
+			want: map[string]string{
+				"in.b":   "// Code generated by B. DO NOT EDIT.\n\nx",
+				"shared": "// Code generated by A. DO NOT EDIT.\n\nx",
+			},
+		},
+		{
+			name: "resolved file replaces previous",
+			jennies: []OneToMany[string]{
+				pathsJenny{name: "A", paths: []string{"shared"}, data: "a"},
+				pathsJenny{name: "B", paths: []string{"shared"}, data: "b"},
+			},
+			resolver: LastWins,
+			want: map[string]string{
+				"shared": "// Code generated by B. DO NOT EDIT.\n\nb",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			jl := JennyListWithNamer(func(s string) string { return s })
+			jl.AppendOneToMany(tc.jennies...)
+			jl.AddPostprocessors(func(f File) (File, error) {
+				// Like GeneratedHeader, which ignores files without an extension.
+				f.Data = append([]byte("// Code generated by "+f.FromString()+". DO NOT EDIT.\n\n"), f.Data...)
+				return f, nil
+			})
+			jl.SetErrorPolicy(PartialResults)
+			jl.SetConflictResolver(tc.resolver)
+
+			jfs, err := jl.GenerateFS("in")
+			if diff := cmp.Diff(tc.want, fsContents(jfs)); diff != "" {
+				t.Errorf("unexpected FS (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantErrs, errStrings(err)); diff != "" {
+				t.Errorf("unexpected errors (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJennyListErrorPolicy(t *testing.T) {
+	// Calls are made in order A(x), A(y), B(x), B(y), C(x), C(y), D(x), D(y).
+	// B fails on y, and C conflicts with A on every input.
+	jennies := []OneToMany[string]{
+		pathsJenny{name: "A", paths: []string{"$.a"}, data: "a"},
+		pathsJenny{name: "B", paths: []string{"$.b"}, data: "b", err: errors.New("generate failed"), failOn: "y"},
+		pathsJenny{name: "C", paths: []string{"$.a"}, data: "c"},
+		pathsJenny{name: "D", paths: []string{"$.d"}, data: "d"},
+	}
+	errB := `B: generate failed for input "y"`
+	errCx := `cannot create x.a for jenny "C", path already created by jenny "A" for input "x"`
+	errCy := `cannot create y.a for jenny "C", path already created by jenny "A" for input "y"`
+
+	tt := []struct {
+		name     string
+		policy   ErrorPolicy
+		want     map[string]string
+		wantErrs []string
+	}{
+		{
+			name:     "collect errors",
+			policy:   CollectErrors,
+			wantErrs: []string{errB, errCx, errCy},
+		},
+		{
+			name:     "fail fast",
+			policy:   FailFast,
+			wantErrs: []string{errB},
+		},
+		{
+			name:   "partial results",
+			policy: PartialResults,
+			want: map[string]string{
+				"x.a": "a",
+				"y.a": "a",
+				"x.b": "b",
+				"x.d": "d",
+				"y.d": "d",
+			},
+			wantErrs: []string{errB, errCx, errCy},
+		},
+	}
+
+	for _, tc := range tt {
+		for _, concurrency := range []int{1, 4} {
+			t.Run(fmt.Sprintf("%s/concurrency %d", tc.name, concurrency), func(t *testing.T) {
+				jl := JennyListWithNamer(func(s string) string { return s })
+				jl.AppendOneToMany(jennies...)
+				jl.SetErrorPolicy(tc.policy)
+				jl.SetConcurrency(concurrency)
+
+				// Repeated, as the order in which concurrent calls complete varies.
+				for i := 0; i < 10; i++ {
+					jfs, err := jl.GenerateFS("x", "y")
+					if diff := cmp.Diff(tc.want, fsContents(jfs)); diff != "" {
+						t.Fatalf("unexpected FS (-want +got):\n%s", diff)
+					}
+					if diff := cmp.Diff(tc.wantErrs, errStrings(err)); diff != "" {
+						t.Fatalf("unexpected errors (-want +got):\n%s", diff)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestJennyError(t *testing.T) {
+	errFailed := errors.New("failed")
+	tt := []struct {
+		name string
+		jl   func() *JennyList[string]
+		want JennyError
+	}{
+		{
+			name: "generate",
+			jl: func() *JennyList[string] {
+				jl := JennyListWithNamer(func(s string) string { return s })
+				jl.AppendOneToMany(pathsJenny{name: "A", err: errFailed})
+				return jl
+			},
+			want: JennyError{InputName: "in", Phase: PhaseGenerate, Err: errFailed},
+		},
+		{
+			name: "validate",
+			jl: func() *JennyList[string] {
+				jl := JennyListWithNamer(func(s string) string { return s })
+				jl.AppendOneToMany(pathsJenny{name: "A", paths: []string{"/abs"}})
+				return jl
+			},
+			want: JennyError{InputName: "in", Phase: PhaseValidate},
+		},
+		{
+			name: "postprocess",
+			jl: func() *JennyList[string] {
+				jl := JennyListWithNamer(func(s string) string { return s })
+				jl.AppendOneToMany(pathsJenny{name: "A", paths: []string{"$.a"}})
+				jl.AddPostprocessors(func(File) (File, error) {
+					return File{}, errFailed
+				})
+				return jl
+			},
+			want: JennyError{InputName: "in", Phase: PhasePostprocess, Path: "in.a", Err: errFailed},
+		},
+		{
+			name: "add",
+			jl: func() *JennyList[string] {
+				jl := JennyListWithNamer(func(s string) string { return s })
+				jl.AppendOneToMany(
+					pathsJenny{name: "B", paths: []string{"$.a"}},
+					pathsJenny{name: "A", paths: []string{"$.a"}},
+				)
+				return jl
+			},
+			want: JennyError{InputName: "in", Phase: PhaseAdd},
+		},
+		{
+			name: "nested",
+			jl: func() *JennyList[string] {
+				inner := JennyListWithNamer(func(s string) string { return s })
+				inner.AppendOneToMany(pathsJenny{name: "A", err: errFailed})
+				jl := JennyListWithNamer(func(s string) string { return s })
+				jl.AppendManyToMany(inner)
+				return jl
+			},
+			want: JennyError{Phase: PhaseGenerate},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.jl().GenerateFS("in")
+			var jerr *JennyError
+			if !errors.As(err, &jerr) {
+				t.Fatalf("expected JennyError, got %v", err)
+			}
+			var stack []string
+			for _, j := range jerr.Stack {
+				stack = append(stack, j.JennyName())
+			}
+			if jerr.Jenny.JennyName() != stack[0] {
+				t.Errorf("Jenny %q is not first in Stack %q", jerr.Jenny.JennyName(), stack)
+			}
+			if stack[len(stack)-1] != "A" {
+				t.Errorf("failing jenny A is not last in Stack %q", stack)
+			}
+			if jerr.InputName != tc.want.InputName || jerr.Phase != tc.want.Phase || jerr.Path != tc.want.Path {
+				t.Errorf("got InputName %q, Phase %q, Path %q, want %q, %q, %q", jerr.InputName, jerr.Phase, jerr.Path, tc.want.InputName, tc.want.Phase, tc.want.Path)
+			}
+			if tc.want.Err != nil && !errors.Is(err, tc.want.Err) {
+				t.Errorf("error does not wrap %v: %v", tc.want.Err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package codejen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DefaultManifestName is the name of the manifest file used by [WithManifest]
+// when no name is provided.
+const DefaultManifestName = ".codejen-manifest.json"
+
+// manifestJenny is the jenny credited with producing the manifest File.
+type manifestJenny struct{}
+
+func (manifestJenny) JennyName() string {
+	return "Manifest"
+}
+
+// manifest is the record of all files emitted by a call to [FS.Write] in
+// manifest mode. It is serialized as JSON.
+type manifest struct {
+	Files []manifestEntry `json:"files"`
+}
+
+type manifestEntry struct {
+	// Path is the slash-separated, prefix-relative path of the file.
+	Path string `json:"path"`
+	// From is the string form of the jenny stack that produced the file.
+	From string `json:"from"`
+	// Policy is the write policy of the file.
+	Policy WritePolicy `json:"policy,omitempty"`
+	// Regions is true if the file has protected regions enabled.
+	Regions bool `json:"regions,omitempty"`
+	// SHA256 is the checksum of the generated contents of the file, per
+	// [checksum], used to detect modification of the file since it was
+	// written.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// checksum returns the hex-encoded SHA-256 of the provided file contents. If
+// regions is true, the contents of protected regions, which are expected to be
+// modified by humans, are excluded.
+func checksum(data []byte, regions bool) string {
+	if regions {
+		data = stripRegions(data)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// buildManifest creates a manifest from the provided files, which are expected
+// to be sorted, as returned from [FS.AsFiles].
+func buildManifest(files []File) *manifest {
+	m := &manifest{
+		Files: make([]manifestEntry, 0, len(files)),
+	}
+	for _, f := range files {
+		m.Files = append(m.Files, manifestEntry{
+			Path:    filepath.ToSlash(f.RelativePath),
+			From:    f.FromString(),
+			Policy:  f.Policy,
+			Regions: f.Regions,
+			SHA256:  checksum(f.Data, f.Regions),
+		})
+	}
+	return m
+}
+
+// toFile serializes the manifest into a File at the provided path.
+func (m *manifest) toFile(name string) (File, error) {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return File{}, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return File{
+		RelativePath: name,
+		Data:         append(b, '\n'),
+		From:         []NamedJenny{manifestJenny{}},
+	}, nil
+}
+
+// orphans returns the entries in the manifest that do not correspond to any of
+// the provided files.
+func (m *manifest) orphans(files []File) []manifestEntry {
+	if m == nil {
+		return nil
+	}
+
+	has := make(map[string]bool, len(files))
+	for _, f := range files {
+		has[filepath.ToSlash(f.RelativePath)] = true
+	}
+
+	var orphans []manifestEntry
+	for _, e := range m.Files {
+		if !has[e.Path] {
+			orphans = append(orphans, e)
+		}
+	}
+	return orphans
+}
+
+// sums returns the checksums of all entries in the manifest, by
+// slash-separated path.
+func (m *manifest) sums() map[string]string {
+	if m == nil {
+		return nil
+	}
+	sums := make(map[string]string, len(m.Files))
+	for _, e := range m.Files {
+		if e.SHA256 != "" {
+			sums[e.Path] = e.SHA256
+		}
+	}
+	return sums
+}
+
+// ownedOrphan reports whether the orphaned file at path, described by the
+// manifest entry e, is owned by humans, and so must not be removed: either it
+// was only ever created by codejen, or it was modified since it was written
+// and its policy keeps modified files, or edit checking is enabled without
+// force ([WithEditCheck]).
+func (o *fsOptions) ownedOrphan(path string, e manifestEntry) (bool, error) {
+	switch {
+	case e.Policy == PolicyCreateOnly:
+		return true, nil
+	case e.Policy == PolicyKeepIfModified, o.editcheck && !o.force:
+		b, err := o.target.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return false, nil
+			}
+			return false, fmt.Errorf("%s: error reading orphaned file: %w", path, err)
+		}
+		if e.SHA256 == "" {
+			// Modification cannot be detected, so only keep files whose policy
+			// requires it.
+			return e.Policy == PolicyKeepIfModified, nil
+		}
+		return checksum(b, e.Regions) != e.SHA256, nil
+	default:
+		return false, nil
+	}
+}
+
+// readManifest reads a manifest from the provided path in the target. A nil
+// manifest and nil error are returned if no manifest exists at the path.
+func readManifest(t Target, path string) (*manifest, error) {
+	b, err := t.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: error reading manifest: %w", path, err)
+	}
+
+	m := new(manifest)
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: invalid manifest: %w", path, err)
+	}
+	for _, e := range m.Files {
+		// Manifest contents determine what Prune deletes, so refuse anything that
+		// could escape the prefix.
+		if !fs.ValidPath(e.Path) || e.Path == "." {
+			return nil, fmt.Errorf("%s: invalid path %q in manifest", path, e.Path)
+		}
+	}
+	return m, nil
+}
+
+// withManifest returns the provided files, with the manifest File appended if
+// manifest mode is enabled.
+func (o *fsOptions) withManifest(files []File) ([]File, error) {
+	if o.manifest == "" {
+		return files, nil
+	}
+
+	for _, f := range files {
+		if filepath.Clean(f.RelativePath) == filepath.Clean(o.manifest) {
+			return nil, fmt.Errorf("cannot write manifest to %s, path already created by jenny %q", o.manifest, f.FromString())
+		}
+	}
+
+	mf, err := buildManifest(files).toFile(o.manifest)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, mf), nil
+}
+
+// previousManifest reads the manifest written by a prior call to [FS.Write]
+// under the provided prefix, if manifest mode is enabled.
+func (o *fsOptions) previousManifest(prefix string) (*manifest, error) {
+	if o.manifest == "" {
+		return nil, nil
+	}
+	return readManifest(o.target, filepath.Join(prefix, o.manifest))
+}
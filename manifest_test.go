@@ -0,0 +1,252 @@
+package codejen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-multierror"
+)
+
+func TestPrune(t *testing.T) {
+	file := func(path string, policy WritePolicy) File {
+		return File{
+			RelativePath: path,
+			Data:         []byte(path + "\n"),
+			From:         []NamedJenny{testJenny("A")},
+			Policy:       policy,
+		}
+	}
+	a := file("a.txt", PolicyOverwrite)
+	b := file("d/e/b.txt", PolicyOverwrite)
+	c := file("d/c.txt", PolicyOverwrite)
+
+	tt := []struct {
+		name string
+		// first is written, then edits are applied to the target, then Verify
+		// and Prune are called with second.
+		first, second []File
+		edits         map[string]string
+		opts          []FSOption
+
+		orphaned []string
+		kept     []string
+		pruned   []string
+		// want is the contents of the target after Prune, excluding the
+		// manifest.
+		want map[string]string
+	}{
+		{
+			name:     "no orphans",
+			first:    []File{a, b},
+			second:   []File{a, b},
+			orphaned: nil,
+			want:     map[string]string{"out": "/", "out/a.txt": "a.txt\n", "out/d": "/", "out/d/e": "/", "out/d/e/b.txt": "d/e/b.txt\n"},
+		},
+		{
+			name:     "orphan removed with empty directories",
+			first:    []File{a, b, c},
+			second:   []File{a, c},
+			orphaned: []string{"d/e/b.txt"},
+			pruned:   []string{"d/e/b.txt"},
+			want:     map[string]string{"out": "/", "out/a.txt": "a.txt\n", "out/d": "/", "out/d/c.txt": "d/c.txt\n"},
+		},
+		{
+			name:     "all orphans removed",
+			first:    []File{a, b, c},
+			second:   []File{a},
+			orphaned: []string{"d/c.txt", "d/e/b.txt"},
+			pruned:   []string{"d/c.txt", "d/e/b.txt"},
+			want:     map[string]string{"out": "/", "out/a.txt": "a.txt\n"},
+		},
+		{
+			name:   "orphan already removed",
+			first:  []File{a, c},
+			second: []File{a},
+			edits:  map[string]string{"out/d/c.txt": ""},
+			want:   map[string]string{"out": "/", "out/a.txt": "a.txt\n", "out/d": "/"},
+		},
+		{
+			name:   "create-only orphan kept",
+			first:  []File{a, file("s.txt", PolicyCreateOnly)},
+			second: []File{a},
+			kept:   []string{"s.txt"},
+			want:   map[string]string{"out": "/", "out/a.txt": "a.txt\n", "out/s.txt": "s.txt\n"},
+		},
+		{
+			name:     "keep-if-modified orphans kept only if modified",
+			first:    []File{a, file("k1.txt", PolicyKeepIfModified), file("k2.txt", PolicyKeepIfModified)},
+			second:   []File{a},
+			edits:    map[string]string{"out/k2.txt": "edited\n"},
+			orphaned: []string{"k1.txt"},
+			kept:     []string{"k2.txt"},
+			pruned:   []string{"k1.txt"},
+			want:     map[string]string{"out": "/", "out/a.txt": "a.txt\n", "out/k2.txt": "edited\n"},
+		},
+		{
+			name:     "hand-edited orphan removed",
+			first:    []File{a, c},
+			second:   []File{a},
+			edits:    map[string]string{"out/d/c.txt": "edited\n"},
+			orphaned: []string{"d/c.txt"},
+			pruned:   []string{"d/c.txt"},
+			want:     map[string]string{"out": "/", "out/a.txt": "a.txt\n"},
+		},
+		{
+			name:   "hand-edited orphan kept with edit check",
+			first:  []File{a, c},
+			second: []File{a},
+			edits:  map[string]string{"out/d/c.txt": "edited\n"},
+			opts:   []FSOption{WithEditCheck()},
+			kept:   []string{"d/c.txt"},
+			want:   map[string]string{"out": "/", "out/a.txt": "a.txt\n", "out/d": "/", "out/d/c.txt": "edited\n"},
+		},
+		{
+			name:     "hand-edited orphan removed with force",
+			first:    []File{a, c},
+			second:   []File{a},
+			edits:    map[string]string{"out/d/c.txt": "edited\n"},
+			opts:     []FSOption{WithEditCheck(), WithForce()},
+			orphaned: []string{"d/c.txt"},
+			pruned:   []string{"d/c.txt"},
+			want:     map[string]string{"out": "/", "out/a.txt": "a.txt\n"},
+		},
+		{
+			name:     "unmodified orphan removed with edit check",
+			first:    []File{a, c},
+			second:   []File{a},
+			opts:     []FSOption{WithEditCheck()},
+			orphaned: []string{"d/c.txt"},
+			pruned:   []string{"d/c.txt"},
+			want:     map[string]string{"out": "/", "out/a.txt": "a.txt\n"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			mt := NewMemTarget()
+			opts := append([]FSOption{WithTarget(mt), WithManifest("")}, tc.opts...)
+
+			first := NewFS()
+			if err := first.Add(tc.first...); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := first.Write(ctx, "out", opts...); err != nil {
+				t.Fatalf("write: %s", err)
+			}
+			for p, data := range tc.edits {
+				var err error
+				if data == "" {
+					err = mt.Remove(p)
+				} else {
+					err = mt.WriteFile(p, []byte(data), 0o644)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			second := NewFS()
+			if err := second.Add(tc.second...); err != nil {
+				t.Fatal(err)
+			}
+			report, err := second.Verify(ctx, "out", opts...)
+			var orphaned []string
+			var merr *multierror.Error
+			if errors.As(err, &merr) {
+				for _, e := range merr.Errors {
+					var cerr *ContentsDifferErr
+					if errors.As(e, &cerr) && cerr.RelativePath == DefaultManifestName {
+						// The manifest no longer lists the orphans.
+						continue
+					}
+					var oerr *OrphanedFileErr
+					if !errors.As(e, &oerr) {
+						t.Fatalf("verify: unexpected error: %s", e)
+					}
+					orphaned = append(orphaned, oerr.RelativePath)
+				}
+			} else if err != nil {
+				t.Fatalf("verify: %s", err)
+			}
+			if diff := cmp.Diff(tc.orphaned, orphaned); diff != "" {
+				t.Errorf("verify: unexpected orphaned files (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.orphaned, report.Paths(StateOrphaned)); diff != "" {
+				t.Errorf("verify: unexpected orphaned files in report (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.kept, report.Paths(StateKept)); diff != "" {
+				t.Errorf("verify: unexpected kept files in report (-want +got):\n%s", diff)
+			}
+
+			report, err = second.Prune(ctx, "out", opts...)
+			if err != nil {
+				t.Fatalf("prune: %s", err)
+			}
+			if diff := cmp.Diff(tc.pruned, report.Paths(StatePruned)); diff != "" {
+				t.Errorf("prune: unexpected pruned files (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.kept, report.Paths(StateKept)); diff != "" {
+				t.Errorf("prune: unexpected kept files (-want +got):\n%s", diff)
+			}
+
+			got := snapshot(t, mt)
+			delete(got, "out/"+DefaultManifestName)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected contents after prune (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	tt := []struct {
+		name     string
+		manifest string
+		wantErr  bool
+	}{
+		{name: "valid", manifest: `{"files": [{"path": "a/b.txt", "from": "A"}]}`},
+		{name: "parent", manifest: `{"files": [{"path": "../x", "from": "A"}]}`, wantErr: true},
+		{name: "nested parent", manifest: `{"files": [{"path": "a/../../x", "from": "A"}]}`, wantErr: true},
+		{name: "absolute", manifest: `{"files": [{"path": "/x", "from": "A"}]}`, wantErr: true},
+		{name: "prefix", manifest: `{"files": [{"path": ".", "from": "A"}]}`, wantErr: true},
+		{name: "invalid json", manifest: `{"files": [`, wantErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := memTarget(t, map[string]string{
+				"x":                          "outside prefix\n",
+				"out/a/b.txt":                "a/b.txt\n",
+				"out/" + DefaultManifestName: tc.manifest,
+			})
+			before := snapshot(t, mt)
+
+			_, err := readManifest(mt, "out/"+DefaultManifestName)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("readManifest: expected error %t, got %v", tc.wantErr, err)
+			}
+
+			// Pruning with an empty FS removes everything in a valid manifest, and
+			// nothing if it is invalid.
+			_, err = NewFS().Prune(context.Background(), "out", WithTarget(mt), WithManifest(""))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("prune: expected error %t, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				if diff := cmp.Diff(before, snapshot(t, mt)); diff != "" {
+					t.Errorf("prune changed target despite invalid manifest (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		m, err := readManifest(NewMemTarget(), "out/"+DefaultManifestName)
+		if m != nil || err != nil {
+			t.Errorf("expected no manifest and no error, got %v, %v", m, err)
+		}
+	})
+}
@@ -1,5 +1,10 @@
 package codejen
 
+import (
+	"context"
+	"fmt"
+)
+
 // ManyToMany is a Jenny that accepts many inputs, and produces 0 to N files as output.
 type ManyToMany[I Input] interface {
 	Jenny[I]
@@ -12,6 +17,17 @@ type ManyToMany[I Input] interface {
 	Generate(...I) (Files, error)
 }
 
+// ManyToManyContext is like [ManyToMany], but its GenerateContext method
+// accepts a [context.Context], allowing the jenny to be cancelled or given a
+// deadline.
+type ManyToManyContext[I Input] interface {
+	Jenny[I]
+
+	// GenerateContext takes a slice of Input and generates many [File]s, or none
+	// (nil) if the jenny was a no-op for the provided Input.
+	GenerateContext(context.Context, ...I) (Files, error)
+}
+
 type m2mAdapt[InI, OutI Input] struct {
 	fn func(OutI) InI
 	j  ManyToMany[InI]
@@ -41,3 +57,170 @@ func AdaptManyToMany[InI, OutI Input](j ManyToMany[InI], fn func(OutI) InI) Many
 		j:  j,
 	}
 }
+
+type m2mAdaptCtx[InI, OutI Input] struct {
+	fn func(context.Context, OutI) (InI, error)
+	j  ManyToManyContext[InI]
+}
+
+func (oa *m2mAdaptCtx[InI, OutI]) JennyName() string {
+	return oa.j.JennyName()
+}
+
+func (oa *m2mAdaptCtx[InI, OutI]) Generate(ps ...OutI) (Files, error) {
+	return oa.GenerateContext(context.Background(), ps...)
+}
+
+func (oa *m2mAdaptCtx[InI, OutI]) GenerateContext(ctx context.Context, ps ...OutI) (Files, error) {
+	qs, err := adaptInputs(ctx, ps, oa.fn)
+	if err != nil {
+		return nil, err
+	}
+	return oa.j.GenerateContext(ctx, qs...)
+}
+
+// AdaptManyToManyErr is like [AdaptManyToMany], but accepts a conversion func that
+// may fail. Errors returned from fn are returned from the adapted jenny, and
+// thereby attributed to the jenny's name.
+//
+// If fn returns [ErrSkipInput] for an input, the adapted jenny is called
+// without it.
+//
+// If j is also a [ManyToManyContext], the returned jenny passes its context
+// through to j.
+func AdaptManyToManyErr[InI, OutI Input](j ManyToMany[InI], fn func(OutI) (InI, error)) ManyToMany[OutI] {
+	jc, ok := j.(ManyToManyContext[InI])
+	if !ok {
+		jc = ContextManyToMany(j)
+	}
+	return &m2mAdaptCtx[InI, OutI]{
+		fn: ignoreContext(fn),
+		j:  jc,
+	}
+}
+
+// AdaptManyToManyContext is like [AdaptManyToManyErr], but for context-aware
+// jennies. The context passed to the adapted jenny is also passed to fn.
+func AdaptManyToManyContext[InI, OutI Input](j ManyToManyContext[InI], fn func(context.Context, OutI) (InI, error)) ManyToManyContext[OutI] {
+	return &m2mAdaptCtx[InI, OutI]{
+		fn: fn,
+		j:  j,
+	}
+}
+
+type m2mCtx[I Input] struct {
+	j ManyToMany[I]
+}
+
+func (ca *m2mCtx[I]) JennyName() string {
+	return ca.j.JennyName()
+}
+
+func (ca *m2mCtx[I]) GenerateContext(ctx context.Context, ts ...I) (Files, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ca.j.Generate(ts...)
+}
+
+// ContextManyToMany adapts a ManyToMany jenny into a [ManyToManyContext] jenny, allowing
+// existing context-free jennies to be used where context-aware jennies are
+// required. The context is checked for cancellation before calling the jenny,
+// but is otherwise ignored.
+func ContextManyToMany[I Input](j ManyToMany[I]) ManyToManyContext[I] {
+	return &m2mCtx[I]{
+		j: j,
+	}
+}
+
+type m2mMap[I Input] struct {
+	fns []FileMapper
+	j   ManyToManyContext[I]
+}
+
+func (m *m2mMap[I]) JennyName() string {
+	return "MapManyToMany"
+}
+
+func (m *m2mMap[I]) Generate(ts ...I) (Files, error) {
+	return m.GenerateContext(context.Background(), ts...)
+}
+
+func (m *m2mMap[I]) GenerateContext(ctx context.Context, ts ...I) (Files, error) {
+	fl, err := m.j.GenerateContext(ctx, ts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.j.JennyName(), err)
+	}
+	if len(fl) == 0 {
+		return fl, nil
+	}
+	return mapFiles(m, m.fns, fl...)
+}
+
+// MapManyToMany takes a ManyToMany jenny and wraps it in a stack of FileMappers to
+// create a new ManyToMany jenny. When Generate is called, the output of the
+// ManyToMany jenny will be transformed by each FileMapper, in order, and the
+// wrapper jenny pushed onto the output's [File.From].
+//
+// Unlike [JennyList.AddPostprocessors], which apply to the output of all jennies
+// in a JennyList, the FileMappers only apply to the output of the wrapped jenny.
+//
+// If j is also a [ManyToManyContext], the returned jenny passes its context
+// through to j.
+func MapManyToMany[I Input](j ManyToMany[I], fn ...FileMapper) ManyToMany[I] {
+	jc, ok := j.(ManyToManyContext[I])
+	if !ok {
+		jc = ContextManyToMany(j)
+	}
+	return &m2mMap[I]{
+		fns: fn,
+		j:   jc,
+	}
+}
+
+type m2mFilter[I Input] struct {
+	pred func(I) bool
+	j    ManyToManyContext[I]
+}
+
+func (fj *m2mFilter[I]) JennyName() string {
+	return "FilterManyToMany"
+}
+
+func (fj *m2mFilter[I]) Generate(ts ...I) (Files, error) {
+	return fj.GenerateContext(context.Background(), ts...)
+}
+
+func (fj *m2mFilter[I]) GenerateContext(ctx context.Context, ts ...I) (Files, error) {
+	filtered := make([]I, 0, len(ts))
+	for _, t := range ts {
+		if fj.pred(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	out, err := fj.j.GenerateContext(ctx, filtered...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fj.j.JennyName(), err)
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+	return mapFiles(fj, nil, out...)
+}
+
+// FilterManyToMany wraps a ManyToMany jenny such that it is only passed the inputs
+// for which pred returns true. The wrapper jenny is pushed onto the
+// [File.From] of all output.
+//
+// If j is also a [ManyToManyContext], the returned jenny passes its context
+// through to j.
+func FilterManyToMany[I Input](j ManyToMany[I], pred func(I) bool) ManyToMany[I] {
+	jc, ok := j.(ManyToManyContext[I])
+	if !ok {
+		jc = ContextManyToMany(j)
+	}
+	return &m2mFilter[I]{
+		pred: pred,
+		j:    jc,
+	}
+}
@@ -1,5 +1,10 @@
 package codejen
 
+import (
+	"context"
+	"fmt"
+)
+
 type ManyToOne[I Input] interface {
 	Jenny[I]
 
@@ -9,6 +14,17 @@ type ManyToOne[I Input] interface {
 	Generate(...I) (*File, error)
 }
 
+// ManyToOneContext is like [ManyToOne], but its GenerateContext method accepts
+// a [context.Context], allowing the jenny to be cancelled or given a deadline.
+type ManyToOneContext[I Input] interface {
+	Jenny[I]
+
+	// GenerateContext takes a slice of Input and generates one File. The zero
+	// value of a File may be returned to indicate the jenny was a no-op for the
+	// provided Inputs.
+	GenerateContext(context.Context, ...I) (*File, error)
+}
+
 type m2oAdapt[InI, OutI Input] struct {
 	fn func(OutI) InI
 	g  ManyToOne[InI]
@@ -38,3 +54,180 @@ func AdaptManyToOne[InI, OutI Input](g ManyToOne[InI], fn func(OutI) InI) ManyTo
 		g:  g,
 	}
 }
+
+type m2oAdaptCtx[InI, OutI Input] struct {
+	fn func(context.Context, OutI) (InI, error)
+	j  ManyToOneContext[InI]
+}
+
+func (oa *m2oAdaptCtx[InI, OutI]) JennyName() string {
+	return oa.j.JennyName()
+}
+
+func (oa *m2oAdaptCtx[InI, OutI]) Generate(ps ...OutI) (*File, error) {
+	return oa.GenerateContext(context.Background(), ps...)
+}
+
+func (oa *m2oAdaptCtx[InI, OutI]) GenerateContext(ctx context.Context, ps ...OutI) (*File, error) {
+	qs, err := adaptInputs(ctx, ps, oa.fn)
+	if err != nil {
+		return nil, err
+	}
+	return oa.j.GenerateContext(ctx, qs...)
+}
+
+// AdaptManyToOneErr is like [AdaptManyToOne], but accepts a conversion func that
+// may fail. Errors returned from fn are returned from the adapted jenny, and
+// thereby attributed to the jenny's name.
+//
+// If fn returns [ErrSkipInput] for an input, the adapted jenny is called
+// without it.
+//
+// If j is also a [ManyToOneContext], the returned jenny passes its context
+// through to j.
+func AdaptManyToOneErr[InI, OutI Input](j ManyToOne[InI], fn func(OutI) (InI, error)) ManyToOne[OutI] {
+	jc, ok := j.(ManyToOneContext[InI])
+	if !ok {
+		jc = ContextManyToOne(j)
+	}
+	return &m2oAdaptCtx[InI, OutI]{
+		fn: ignoreContext(fn),
+		j:  jc,
+	}
+}
+
+// AdaptManyToOneContext is like [AdaptManyToOneErr], but for context-aware
+// jennies. The context passed to the adapted jenny is also passed to fn.
+func AdaptManyToOneContext[InI, OutI Input](j ManyToOneContext[InI], fn func(context.Context, OutI) (InI, error)) ManyToOneContext[OutI] {
+	return &m2oAdaptCtx[InI, OutI]{
+		fn: fn,
+		j:  j,
+	}
+}
+
+type m2oCtx[I Input] struct {
+	j ManyToOne[I]
+}
+
+func (ca *m2oCtx[I]) JennyName() string {
+	return ca.j.JennyName()
+}
+
+func (ca *m2oCtx[I]) GenerateContext(ctx context.Context, ts ...I) (*File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ca.j.Generate(ts...)
+}
+
+// ContextManyToOne adapts a ManyToOne jenny into a [ManyToOneContext] jenny, allowing
+// existing context-free jennies to be used where context-aware jennies are
+// required. The context is checked for cancellation before calling the jenny,
+// but is otherwise ignored.
+func ContextManyToOne[I Input](j ManyToOne[I]) ManyToOneContext[I] {
+	return &m2oCtx[I]{
+		j: j,
+	}
+}
+
+type m2oMap[I Input] struct {
+	fns []FileMapper
+	j   ManyToOneContext[I]
+}
+
+func (m *m2oMap[I]) JennyName() string {
+	return "MapManyToOne"
+}
+
+func (m *m2oMap[I]) Generate(ts ...I) (*File, error) {
+	return m.GenerateContext(context.Background(), ts...)
+}
+
+func (m *m2oMap[I]) GenerateContext(ctx context.Context, ts ...I) (*File, error) {
+	f, err := m.j.GenerateContext(ctx, ts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.j.JennyName(), err)
+	}
+	if f == nil || !f.Exists() {
+		return f, nil
+	}
+
+	fl, err := mapFiles(m, m.fns, *f)
+	if err != nil {
+		return nil, err
+	}
+	return &fl[0], nil
+}
+
+// MapManyToOne takes a ManyToOne jenny and wraps it in a stack of FileMappers to
+// create a new ManyToOne jenny. When Generate is called, the output of the
+// ManyToOne jenny will be transformed by each FileMapper, in order, and the
+// wrapper jenny pushed onto the output's [File.From].
+//
+// Unlike [JennyList.AddPostprocessors], which apply to the output of all jennies
+// in a JennyList, the FileMappers only apply to the output of the wrapped jenny.
+//
+// If j is also a [ManyToOneContext], the returned jenny passes its context
+// through to j.
+func MapManyToOne[I Input](j ManyToOne[I], fn ...FileMapper) ManyToOne[I] {
+	jc, ok := j.(ManyToOneContext[I])
+	if !ok {
+		jc = ContextManyToOne(j)
+	}
+	return &m2oMap[I]{
+		fns: fn,
+		j:   jc,
+	}
+}
+
+type m2oFilter[I Input] struct {
+	pred func(I) bool
+	j    ManyToOneContext[I]
+}
+
+func (fj *m2oFilter[I]) JennyName() string {
+	return "FilterManyToOne"
+}
+
+func (fj *m2oFilter[I]) Generate(ts ...I) (*File, error) {
+	return fj.GenerateContext(context.Background(), ts...)
+}
+
+func (fj *m2oFilter[I]) GenerateContext(ctx context.Context, ts ...I) (*File, error) {
+	filtered := make([]I, 0, len(ts))
+	for _, t := range ts {
+		if fj.pred(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	out, err := fj.j.GenerateContext(ctx, filtered...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fj.j.JennyName(), err)
+	}
+	if out == nil || !out.Exists() {
+		return out, nil
+	}
+
+	fl, err := mapFiles(fj, nil, *out)
+	if err != nil {
+		return nil, err
+	}
+	return &fl[0], nil
+}
+
+// FilterManyToOne wraps a ManyToOne jenny such that it is only passed the inputs
+// for which pred returns true. The wrapper jenny is pushed onto the
+// [File.From] of all output.
+//
+// If j is also a [ManyToOneContext], the returned jenny passes its context
+// through to j.
+func FilterManyToOne[I Input](j ManyToOne[I], pred func(I) bool) ManyToOne[I] {
+	jc, ok := j.(ManyToOneContext[I])
+	if !ok {
+		jc = ContextManyToOne(j)
+	}
+	return &m2oFilter[I]{
+		pred: pred,
+		j:    jc,
+	}
+}
@@ -38,6 +38,9 @@ type mapFile struct {
 	Mode    fs.FileMode // FileInfo.Mode
 	ModTime time.Time   // FileInfo.ModTime
 	Sys     any         // FileInfo.Sys
+
+	policy  WritePolicy // codejen: File.Policy
+	regions bool        // codejen: File.Regions
 }
 
 var _ fs.FS = mapFS(nil)
@@ -1,5 +1,10 @@
 package codejen
 
+import (
+	"context"
+	"fmt"
+)
+
 type OneToMany[I Input] interface {
 	Jenny[I]
 
@@ -8,6 +13,16 @@ type OneToMany[I Input] interface {
 	Generate(I) (Files, error)
 }
 
+// OneToManyContext is like [OneToMany], but its GenerateContext method accepts
+// a [context.Context], allowing the jenny to be cancelled or given a deadline.
+type OneToManyContext[I Input] interface {
+	Jenny[I]
+
+	// GenerateContext takes an Input and generates many [File]s, or none (nil)
+	// if the jenny was a no-op for the provided Input.
+	GenerateContext(context.Context, I) (Files, error)
+}
+
 type o2mAdapt[InI, OutI Input] struct {
 	fn func(OutI) InI
 	j  OneToMany[InI]
@@ -33,3 +48,167 @@ func AdaptOneToMany[InI, OutI Input](j OneToMany[InI], fn func(OutI) InI) OneToM
 		j:  j,
 	}
 }
+
+type o2mAdaptCtx[InI, OutI Input] struct {
+	fn func(context.Context, OutI) (InI, error)
+	j  OneToManyContext[InI]
+}
+
+func (oa *o2mAdaptCtx[InI, OutI]) JennyName() string {
+	return oa.j.JennyName()
+}
+
+func (oa *o2mAdaptCtx[InI, OutI]) Generate(t OutI) (Files, error) {
+	return oa.GenerateContext(context.Background(), t)
+}
+
+func (oa *o2mAdaptCtx[InI, OutI]) GenerateContext(ctx context.Context, t OutI) (Files, error) {
+	q, skip, err := adaptInput(ctx, t, oa.fn)
+	if skip || err != nil {
+		return nil, err
+	}
+	return oa.j.GenerateContext(ctx, q)
+}
+
+// AdaptOneToManyErr is like [AdaptOneToMany], but accepts a conversion func that
+// may fail. Errors returned from fn are returned from the adapted jenny, and
+// thereby attributed to the jenny's name.
+//
+// If fn returns [ErrSkipInput], the input is skipped, and the adapted jenny
+// is not called for it.
+//
+// If j is also a [OneToManyContext], the returned jenny passes its context
+// through to j.
+func AdaptOneToManyErr[InI, OutI Input](j OneToMany[InI], fn func(OutI) (InI, error)) OneToMany[OutI] {
+	jc, ok := j.(OneToManyContext[InI])
+	if !ok {
+		jc = ContextOneToMany(j)
+	}
+	return &o2mAdaptCtx[InI, OutI]{
+		fn: ignoreContext(fn),
+		j:  jc,
+	}
+}
+
+// AdaptOneToManyContext is like [AdaptOneToManyErr], but for context-aware
+// jennies. The context passed to the adapted jenny is also passed to fn.
+func AdaptOneToManyContext[InI, OutI Input](j OneToManyContext[InI], fn func(context.Context, OutI) (InI, error)) OneToManyContext[OutI] {
+	return &o2mAdaptCtx[InI, OutI]{
+		fn: fn,
+		j:  j,
+	}
+}
+
+type o2mCtx[I Input] struct {
+	j OneToMany[I]
+}
+
+func (ca *o2mCtx[I]) JennyName() string {
+	return ca.j.JennyName()
+}
+
+func (ca *o2mCtx[I]) GenerateContext(ctx context.Context, t I) (Files, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ca.j.Generate(t)
+}
+
+// ContextOneToMany adapts a OneToMany jenny into a [OneToManyContext] jenny, allowing
+// existing context-free jennies to be used where context-aware jennies are
+// required. The context is checked for cancellation before calling the jenny,
+// but is otherwise ignored.
+func ContextOneToMany[I Input](j OneToMany[I]) OneToManyContext[I] {
+	return &o2mCtx[I]{
+		j: j,
+	}
+}
+
+type o2mMap[I Input] struct {
+	fns []FileMapper
+	j   OneToManyContext[I]
+}
+
+func (m *o2mMap[I]) JennyName() string {
+	return "MapOneToMany"
+}
+
+func (m *o2mMap[I]) Generate(t I) (Files, error) {
+	return m.GenerateContext(context.Background(), t)
+}
+
+func (m *o2mMap[I]) GenerateContext(ctx context.Context, t I) (Files, error) {
+	fl, err := m.j.GenerateContext(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.j.JennyName(), err)
+	}
+	if len(fl) == 0 {
+		return fl, nil
+	}
+	return mapFiles(m, m.fns, fl...)
+}
+
+// MapOneToMany takes a OneToMany jenny and wraps it in a stack of FileMappers to
+// create a new OneToMany jenny. When Generate is called, the output of the
+// OneToMany jenny will be transformed by each FileMapper, in order, and the
+// wrapper jenny pushed onto the output's [File.From].
+//
+// Unlike [JennyList.AddPostprocessors], which apply to the output of all jennies
+// in a JennyList, the FileMappers only apply to the output of the wrapped jenny.
+//
+// If j is also a [OneToManyContext], the returned jenny passes its context
+// through to j.
+func MapOneToMany[I Input](j OneToMany[I], fn ...FileMapper) OneToMany[I] {
+	jc, ok := j.(OneToManyContext[I])
+	if !ok {
+		jc = ContextOneToMany(j)
+	}
+	return &o2mMap[I]{
+		fns: fn,
+		j:   jc,
+	}
+}
+
+type o2mFilter[I Input] struct {
+	pred func(I) bool
+	j    OneToManyContext[I]
+}
+
+func (fj *o2mFilter[I]) JennyName() string {
+	return "FilterOneToMany"
+}
+
+func (fj *o2mFilter[I]) Generate(t I) (Files, error) {
+	return fj.GenerateContext(context.Background(), t)
+}
+
+func (fj *o2mFilter[I]) GenerateContext(ctx context.Context, t I) (Files, error) {
+	if !fj.pred(t) {
+		return nil, nil
+	}
+	out, err := fj.j.GenerateContext(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fj.j.JennyName(), err)
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+	return mapFiles(fj, nil, out...)
+}
+
+// FilterOneToMany wraps a OneToMany jenny such that it is only called for inputs
+// for which pred returns true, and is a no-op for all others. The wrapper jenny
+// is pushed onto the [File.From] of all output.
+//
+// If j is also a [OneToManyContext], the returned jenny passes its context
+// through to j.
+func FilterOneToMany[I Input](j OneToMany[I], pred func(I) bool) OneToMany[I] {
+	jc, ok := j.(OneToManyContext[I])
+	if !ok {
+		jc = ContextOneToMany(j)
+	}
+	return &o2mFilter[I]{
+		pred: pred,
+		j:    jc,
+	}
+}
@@ -1,5 +1,10 @@
 package codejen
 
+import (
+	"context"
+	"fmt"
+)
+
 type OneToOne[I Input] interface {
 	Jenny[I]
 
@@ -8,6 +13,17 @@ type OneToOne[I Input] interface {
 	Generate(I) (*File, error)
 }
 
+// OneToOneContext is like [OneToOne], but its GenerateContext method accepts a
+// [context.Context], allowing the jenny to be cancelled or given a deadline.
+type OneToOneContext[I Input] interface {
+	Jenny[I]
+
+	// GenerateContext takes an Input and generates one [File]. The zero value of
+	// a File may be returned to indicate the jenny was a no-op for the provided
+	// Input.
+	GenerateContext(context.Context, I) (*File, error)
+}
+
 type o2oAdapt[InI, OutI Input] struct {
 	fn func(OutI) InI
 	j  OneToOne[InI]
@@ -34,9 +50,176 @@ func AdaptOneToOne[InI, OutI Input](j OneToOne[InI], fn func(OutI) InI) OneToOne
 	}
 }
 
+type o2oAdaptCtx[InI, OutI Input] struct {
+	fn func(context.Context, OutI) (InI, error)
+	j  OneToOneContext[InI]
+}
+
+func (oa *o2oAdaptCtx[InI, OutI]) JennyName() string {
+	return oa.j.JennyName()
+}
+
+func (oa *o2oAdaptCtx[InI, OutI]) Generate(t OutI) (*File, error) {
+	return oa.GenerateContext(context.Background(), t)
+}
+
+func (oa *o2oAdaptCtx[InI, OutI]) GenerateContext(ctx context.Context, t OutI) (*File, error) {
+	q, skip, err := adaptInput(ctx, t, oa.fn)
+	if skip || err != nil {
+		return nil, err
+	}
+	return oa.j.GenerateContext(ctx, q)
+}
+
+// AdaptOneToOneErr is like [AdaptOneToOne], but accepts a conversion func that
+// may fail. Errors returned from fn are returned from the adapted jenny, and
+// thereby attributed to the jenny's name.
+//
+// If fn returns [ErrSkipInput], the input is skipped, and the adapted jenny
+// is not called for it.
+//
+// If j is also a [OneToOneContext], the returned jenny passes its context
+// through to j.
+func AdaptOneToOneErr[InI, OutI Input](j OneToOne[InI], fn func(OutI) (InI, error)) OneToOne[OutI] {
+	jc, ok := j.(OneToOneContext[InI])
+	if !ok {
+		jc = ContextOneToOne(j)
+	}
+	return &o2oAdaptCtx[InI, OutI]{
+		fn: ignoreContext(fn),
+		j:  jc,
+	}
+}
+
+// AdaptOneToOneContext is like [AdaptOneToOneErr], but for context-aware
+// jennies. The context passed to the adapted jenny is also passed to fn.
+func AdaptOneToOneContext[InI, OutI Input](j OneToOneContext[InI], fn func(context.Context, OutI) (InI, error)) OneToOneContext[OutI] {
+	return &o2oAdaptCtx[InI, OutI]{
+		fn: fn,
+		j:  j,
+	}
+}
+
+type o2oMap[I Input] struct {
+	fns []FileMapper
+	j   OneToOneContext[I]
+}
+
+func (m *o2oMap[I]) JennyName() string {
+	return "MapOneToOne"
+}
+
+func (m *o2oMap[I]) Generate(t I) (*File, error) {
+	return m.GenerateContext(context.Background(), t)
+}
+
+func (m *o2oMap[I]) GenerateContext(ctx context.Context, t I) (*File, error) {
+	f, err := m.j.GenerateContext(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.j.JennyName(), err)
+	}
+	if f == nil || !f.Exists() {
+		return f, nil
+	}
+
+	fl, err := mapFiles(m, m.fns, *f)
+	if err != nil {
+		return nil, err
+	}
+	return &fl[0], nil
+}
+
 // MapOneToOne takes a OneToOne jenny and wraps it in a stack of FileMappers to create a
 // new OneToOne jenny. When Generate is called, the output of the OneToOne jenny will be
-// transformed
-// func MapOneToOne[I Input](j OneToOne[I], fn ...FileMapper) OneToOne[I] {
+// transformed by each FileMapper, in order, and the wrapper jenny pushed onto
+// the output's [File.From].
 //
-// }
+// Unlike [JennyList.AddPostprocessors], which apply to the output of all jennies
+// in a JennyList, the FileMappers only apply to the output of the wrapped jenny.
+//
+// If j is also a [OneToOneContext], the returned jenny passes its context
+// through to j.
+func MapOneToOne[I Input](j OneToOne[I], fn ...FileMapper) OneToOne[I] {
+	jc, ok := j.(OneToOneContext[I])
+	if !ok {
+		jc = ContextOneToOne(j)
+	}
+	return &o2oMap[I]{
+		fns: fn,
+		j:   jc,
+	}
+}
+
+type o2oCtx[I Input] struct {
+	j OneToOne[I]
+}
+
+func (ca *o2oCtx[I]) JennyName() string {
+	return ca.j.JennyName()
+}
+
+func (ca *o2oCtx[I]) GenerateContext(ctx context.Context, t I) (*File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ca.j.Generate(t)
+}
+
+// ContextOneToOne adapts a OneToOne jenny into a [OneToOneContext] jenny, allowing
+// existing context-free jennies to be used where context-aware jennies are
+// required. The context is checked for cancellation before calling the jenny,
+// but is otherwise ignored.
+func ContextOneToOne[I Input](j OneToOne[I]) OneToOneContext[I] {
+	return &o2oCtx[I]{
+		j: j,
+	}
+}
+
+type o2oFilter[I Input] struct {
+	pred func(I) bool
+	j    OneToOneContext[I]
+}
+
+func (fj *o2oFilter[I]) JennyName() string {
+	return "FilterOneToOne"
+}
+
+func (fj *o2oFilter[I]) Generate(t I) (*File, error) {
+	return fj.GenerateContext(context.Background(), t)
+}
+
+func (fj *o2oFilter[I]) GenerateContext(ctx context.Context, t I) (*File, error) {
+	if !fj.pred(t) {
+		return nil, nil
+	}
+	out, err := fj.j.GenerateContext(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fj.j.JennyName(), err)
+	}
+	if out == nil || !out.Exists() {
+		return out, nil
+	}
+
+	fl, err := mapFiles(fj, nil, *out)
+	if err != nil {
+		return nil, err
+	}
+	return &fl[0], nil
+}
+
+// FilterOneToOne wraps a OneToOne jenny such that it is only called for inputs
+// for which pred returns true, and is a no-op for all others. The wrapper jenny
+// is pushed onto the [File.From] of all output.
+//
+// If j is also a [OneToOneContext], the returned jenny passes its context
+// through to j.
+func FilterOneToOne[I Input](j OneToOne[I], pred func(I) bool) OneToOne[I] {
+	jc, ok := j.(OneToOneContext[I])
+	if !ok {
+		jc = ContextOneToOne(j)
+	}
+	return &o2oFilter[I]{
+		pred: pred,
+		j:    jc,
+	}
+}
@@ -0,0 +1,229 @@
+package codejen
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FSOption configures the behavior of [FS.Write], [FS.Verify] and [FS.Prune].
+type FSOption func(*fsOptions)
+
+type fsOptions struct {
+	// manifest is the prefix-relative path of the manifest file. Empty
+	// disables manifest mode.
+	manifest string
+
+	// atomic enables transactional writes.
+	atomic bool
+
+	// concurrency is the maximum number of files processed concurrently.
+	concurrency int
+
+	// fileMode is the permission with which files are created.
+	fileMode fs.FileMode
+
+	// dirMode is the permission with which directories are created.
+	dirMode fs.FileMode
+
+	// fsync enables syncing each written file to stable storage.
+	fsync bool
+
+	// nofollow disables following symlinks at generated paths.
+	nofollow bool
+
+	// target is the filesystem to which files are written.
+	target Target
+
+	// editcheck enables detection of hand-edited files in Write.
+	editcheck bool
+
+	// force makes Write overwrite hand-edited files.
+	force bool
+}
+
+func newFSOptions(opts ...FSOption) *fsOptions {
+	o := &fsOptions{
+		concurrency: 12,
+		fileMode:    0644,
+		dirMode:     os.ModePerm,
+		target:      OSTarget{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithManifest enables manifest mode, in which [FS.Write] records every path it
+// emitted in a manifest file at the provided path, relative to the prefix. If
+// name is empty, [DefaultManifestName] is used.
+//
+// [FS.Verify] and [FS.Prune] compare the previously written manifest against the
+// contents of the FS to find orphaned files - files that were generated on a
+// prior run, but are no longer generated.
+func WithManifest(name string) FSOption {
+	return func(o *fsOptions) {
+		if name == "" {
+			name = DefaultManifestName
+		}
+		o.manifest = name
+	}
+}
+
+// WithAtomic enables transactional writes in [FS.Write]. Every file is first
+// staged into a temporary file next to its target, and staged files are only
+// renamed into place after all of them were written successfully. If any step
+// fails or the context is cancelled, the original contents of overwritten files
+// are restored, and created files and directories are removed.
+//
+// Restoration is best effort, as the filesystem may also fail while restoring.
+//
+// As in non-atomic mode, files at symlinked paths are written through the
+// symlink, replacing the file it links to, and leaving the symlink in place.
+// This is only supported on [OSTarget]; on other targets, Write fails for any
+// generated path that is a symlink.
+func WithAtomic() FSOption {
+	return func(o *fsOptions) {
+		o.atomic = true
+	}
+}
+
+// WithConcurrency sets the maximum number of files that are processed
+// concurrently. The default is 12. A value less than 1 removes the limit.
+func WithConcurrency(n int) FSOption {
+	return func(o *fsOptions) {
+		if n < 1 {
+			// errgroup treats a negative limit as unlimited, but 0 as allowing
+			// no goroutines at all.
+			n = -1
+		}
+		o.concurrency = n
+	}
+}
+
+// WithFileMode sets the permissions with which files are written. The default
+// is 0644. Permissions of existing files are not changed.
+func WithFileMode(perm fs.FileMode) FSOption {
+	return func(o *fsOptions) {
+		o.fileMode = perm.Perm()
+	}
+}
+
+// WithDirMode sets the permissions with which missing parent directories are
+// created. The default is [os.ModePerm]. Permissions of existing directories
+// are not changed.
+func WithDirMode(perm fs.FileMode) FSOption {
+	return func(o *fsOptions) {
+		o.dirMode = perm.Perm()
+	}
+}
+
+// WithFsync makes [FS.Write] sync each written file to stable storage before
+// considering it written. This is slower, but guards against loss of generated
+// files on power failure.
+func WithFsync() FSOption {
+	return func(o *fsOptions) {
+		o.fsync = true
+	}
+}
+
+// WithFollowSymlinks sets whether symlinks found at generated paths are
+// followed. The default is true. If false, [FS.Write] and [FS.Verify] return an
+// error for any generated path that is a symlink, rather than writing or
+// reading through it.
+func WithFollowSymlinks(follow bool) FSOption {
+	return func(o *fsOptions) {
+		o.nofollow = !follow
+	}
+}
+
+// checkSymlink returns an error if path is a symlink and following symlinks is
+// disabled.
+func (o *fsOptions) checkSymlink(path string) error {
+	if !o.nofollow {
+		return nil
+	}
+	if fi, err := o.target.Lstat(path); err == nil && fi.Mode()&fs.ModeSymlink != 0 {
+		return fmt.Errorf("%s: is a symlink, and following symlinks is disabled", path)
+	}
+	return nil
+}
+
+// resolveSymlink returns the path of the file that path links to, if path is a
+// symlink, and path itself otherwise.
+func (o *fsOptions) resolveSymlink(path string) (string, error) {
+	fi, err := o.target.Lstat(path)
+	if err != nil || fi.Mode()&fs.ModeSymlink == 0 {
+		return path, nil
+	}
+	if _, ok := o.target.(OSTarget); !ok {
+		return "", fmt.Errorf("%s: is a symlink, which atomic writes only support on the real filesystem", path)
+	}
+	rpath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: could not resolve symlink: %w", path, err)
+	}
+	return rpath, nil
+}
+
+// writeFile writes data to the named file, creating it with the provided
+// permissions if necessary, and syncing it if fsync is enabled.
+func (o *fsOptions) writeFile(path string, data []byte, perm fs.FileMode) error {
+	if err := o.target.WriteFile(path, data, perm); err != nil {
+		return err
+	}
+	if o.fsync {
+		return o.target.Sync(path)
+	}
+	return nil
+}
+
+// WithTarget sets the [Target] filesystem to which [FS.Write] writes, and
+// against which [FS.Verify] and [FS.Prune] operate. The default is [OSTarget].
+func WithTarget(t Target) FSOption {
+	return func(o *fsOptions) {
+		o.target = t
+	}
+}
+
+// abspath returns the absolute form of path if the target is the real
+// filesystem, and path itself otherwise, or if it cannot be made absolute.
+func (o *fsOptions) abspath(path string) string {
+	if _, ok := o.target.(OSTarget); !ok {
+		return path
+	}
+	if apath, err := filepath.Abs(path); err == nil {
+		return apath
+	}
+	return path
+}
+
+// WithEditCheck makes [FS.Write] refuse to overwrite generated files that were
+// edited by hand since they were last written. A file is considered hand-edited
+// if its contents on disk, excluding protected regions, no longer match the
+// checksum recorded in the manifest of the previous Write, and differ from its
+// newly generated contents. Files not recorded in the previous manifest, and
+// those with a [File.Policy] that already leaves them in place, are never
+// considered hand-edited.
+//
+// If any file was hand-edited, Write writes nothing, and returns a
+// [*HandEditedErr] for each such file. Use [WithForce] to overwrite them
+// anyway. Likewise, [FS.Prune] does not remove hand-edited orphaned files
+// unless forced, and [FS.Verify] does not report them as orphaned.
+//
+// WithEditCheck requires manifest mode ([WithManifest]).
+func WithEditCheck() FSOption {
+	return func(o *fsOptions) {
+		o.editcheck = true
+	}
+}
+
+// WithForce makes [FS.Write] overwrite hand-edited files detected by
+// [WithEditCheck].
+func WithForce() FSOption {
+	return func(o *fsOptions) {
+		o.force = true
+	}
+}
@@ -0,0 +1,184 @@
+package codejen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+var (
+	_ ManyToMany[any]        = &Pipeline[any]{}
+	_ ManyToManyContext[any] = &Pipeline[any]{}
+)
+
+// Pipeline is a chain of code generation stages, in which the Files output by
+// each stage are the inputs to the next stage. For example, a first stage may
+// generate OpenAPI documents from schemas, and a second stage generate
+// TypeScript clients from those OpenAPI documents.
+//
+// The first stage takes the Pipeline's Input type. All subsequent stages take
+// [File] as input. Each stage is composed of one or more jennies, which behave
+// as if they were in a [JennyList].
+//
+// Pipeline itself implements [ManyToMany], and when called, returns the
+// outputs of all stages, merged into a single path namespace. Path uniqueness
+// is enforced across all stages.
+//
+// When a jenny in a later stage generates Files from an input File, the input
+// File's [File.From] is appended to the From of each generated File, recording
+// the full chain of jennies that produced it. For [ManyToOne] and [ManyToMany]
+// jennies, this is only done if all input Files share the same From, as a
+// chain through inputs from different jennies cannot be represented as a
+// single stack.
+//
+// Each stage is backed by a JennyList, accessible through [Pipeline.Stage], on
+// which postprocessors, concurrency, an error policy and a conflict resolver
+// may be set. Any error from a stage, including under [PartialResults], fails
+// the Pipeline.
+type Pipeline[I Input] struct {
+	mut sync.RWMutex
+
+	first  *JennyList[I]
+	stages []*JennyList[File]
+}
+
+// NewPipeline creates a new Pipeline, with the provided jennies as its first
+// stage.
+//
+// All provided jennies must implement one of [OneToOne], [OneToMany],
+// [ManyToOne], [ManyToMany], or their context-aware variants, or this func will
+// panic.
+func NewPipeline[I Input](jennies ...Jenny[I]) *Pipeline[I] {
+	first := &JennyList[I]{}
+	first.Append(jennies...)
+	return &Pipeline[I]{
+		first: first,
+	}
+}
+
+// Then adds a stage to the end of the Pipeline, composed of the provided
+// jennies. Each of the jennies is called with the Files output by the
+// preceding stage. The Pipeline is returned to allow chaining.
+//
+// All provided jennies must implement one of [OneToOne], [OneToMany],
+// [ManyToOne], [ManyToMany], or their context-aware variants, or this method
+// will panic.
+func (p *Pipeline[I]) Then(jennies ...Jenny[File]) *Pipeline[I] {
+	stage := newFileJennyList()
+	stage.Append(jennies...)
+
+	p.mut.Lock()
+	p.stages = append(p.stages, stage)
+	p.mut.Unlock()
+	return p
+}
+
+// First returns the JennyList backing the first stage of the Pipeline, to
+// allow configuring it. Jennies may also be appended to it.
+func (p *Pipeline[I]) First() *JennyList[I] {
+	return p.first
+}
+
+// Stage returns the JennyList backing the nth stage added with
+// [Pipeline.Then], counting from 1, to allow configuring it. The first stage,
+// numbered 0 in errors, is returned by [Pipeline.First]. Stage panics if there
+// is no such stage.
+func (p *Pipeline[I]) Stage(n int) *JennyList[File] {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	if n < 1 || n > len(p.stages) {
+		panic(fmt.Sprintf("codejen: Pipeline has no stage %d", n))
+	}
+	return p.stages[n-1]
+}
+
+func (p *Pipeline[I]) JennyName() string {
+	return fmt.Sprintf("Pipeline[%T]", new(I))
+}
+
+// GenerateFS runs each stage of the Pipeline in order, and returns an FS
+// containing the outputs of all stages.
+func (p *Pipeline[I]) GenerateFS(objs ...I) (*FS, error) {
+	return p.GenerateFSContext(context.Background(), objs...)
+}
+
+// GenerateFSContext is like [Pipeline.GenerateFS], but passes ctx to all
+// context-aware jennies.
+func (p *Pipeline[I]) GenerateFSContext(ctx context.Context, objs ...I) (*FS, error) {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+
+	sfs, err := p.first.GenerateFSContext(ctx, objs...)
+	if err != nil {
+		return nil, fmt.Errorf("stage 0: %w", err)
+	}
+
+	pfs := NewFS()
+	for i, stage := range p.stages {
+		if err := pfs.Merge(sfs); err != nil {
+			return nil, err
+		}
+		sfs, err = stage.GenerateFSContext(ctx, sfs.AsFiles()...)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d: %w", i+1, err)
+		}
+	}
+	if err := pfs.Merge(sfs); err != nil {
+		return nil, err
+	}
+	return pfs, nil
+}
+
+func (p *Pipeline[I]) Generate(objs ...I) (Files, error) {
+	return p.GenerateContext(context.Background(), objs...)
+}
+
+func (p *Pipeline[I]) GenerateContext(ctx context.Context, objs ...I) (Files, error) {
+	pfs, err := p.GenerateFSContext(ctx, objs...)
+	if err != nil {
+		return nil, err
+	}
+	return pfs.AsFiles(), nil
+}
+
+// newFileJennyList creates a JennyList for a stage of a Pipeline or a dependent
+// jenny of a JennyGraph, taking Files as input. Inputs are named by their path,
+// and the From of inputs is appended to that of generated Files.
+func newFileJennyList() *JennyList[File] {
+	jl := JennyListWithNamer(func(f File) string {
+		return f.RelativePath
+	})
+	jl.lineage = true
+	return jl
+}
+
+// lineageOf returns the jenny stack to append to the From of Files generated
+// from the provided inputs. If all inputs are Files sharing the same From,
+// that From is returned. Otherwise, the chain cannot be represented as a
+// single stack, and nil is returned.
+func lineageOf[Input any](ins ...Input) []NamedJenny {
+	var from []NamedJenny
+	for i, in := range ins {
+		f, ok := any(in).(File)
+		if !ok {
+			return nil
+		}
+		if i == 0 {
+			from = f.From
+		} else if jennystack(f.From).String() != jennystack(from).String() {
+			return nil
+		}
+	}
+	return from
+}
+
+// appendFrom appends from to the From of each of the provided Files.
+func appendFrom(fl Files, from []NamedJenny) {
+	if len(from) == 0 {
+		return
+	}
+	for i, f := range fl {
+		nfrom := make([]NamedJenny, 0, len(f.From)+len(from))
+		fl[i].From = append(append(nfrom, f.From...), from...)
+	}
+}
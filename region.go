@@ -0,0 +1,261 @@
+package codejen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Protected regions are sections of a generated file whose contents are
+// written by humans, and preserved across regeneration. A jenny enables them
+// by setting [File.Regions], and declares each region by emitting a pair of
+// marker lines, typically as comments in the syntax of the generated language:
+//
+//	// codejen:region handler
+//	// TODO: implement
+//	// codejen:endregion handler
+//
+// The lines between the markers are the default contents of the region, used
+// when the file is first created. When [FS.Write] overwrites an existing file,
+// the contents of each region in the existing file are carried into the
+// region of the same name in the generated file. [FS.Verify] likewise ignores
+// differences within regions.
+//
+// A marker line must contain only the marker and the region name, optionally
+// within a single-line comment; markers elsewhere, such as in prose, are
+// ignored. Region names may not contain whitespace, and must be unique within
+// a file. Regions may not be nested.
+const (
+	// RegionBeginMarker begins a protected region. It must be followed on the
+	// same line by whitespace and the name of the region.
+	RegionBeginMarker = "codejen:region"
+
+	// RegionEndMarker ends a protected region. It must be followed on the same
+	// line by whitespace and the name of the region it ends.
+	RegionEndMarker = "codejen:endregion"
+)
+
+// OrphanedRegionErr is an error that indicates a file on disk contains a
+// protected region that is no longer present in the generated file. Its
+// contents would be discarded by [FS.Write].
+type OrphanedRegionErr struct {
+	// Path is the absolute path of the file on disk, or the path within the
+	// Target for targets other than [OSTarget].
+	Path string
+
+	// RelativePath is the path of the file within the FS.
+	RelativePath string
+
+	// From is the stack of jennies responsible for producing the file.
+	From []NamedJenny
+
+	// Region is the name of the orphaned region.
+	Region string
+}
+
+func (e *OrphanedRegionErr) Error() string {
+	return fmt.Sprintf("%s: region %q no longer generated, its contents would be discarded", e.Path, e.Region)
+}
+
+// InvalidRegionsErr is an error that indicates the protected regions of a file
+// are malformed, either in the generated file or in the file on disk, such as
+// by a marker having been removed by hand. The regions of the file on disk
+// cannot be carried over.
+type InvalidRegionsErr struct {
+	// Path is the absolute path of the file on disk, or the path within the
+	// Target for targets other than [OSTarget].
+	Path string
+
+	// RelativePath is the path of the file within the FS.
+	RelativePath string
+
+	// From is the stack of jennies responsible for producing the file.
+	From []NamedJenny
+
+	// Err describes what is wrong with the regions.
+	Err error
+}
+
+func (e *InvalidRegionsErr) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *InvalidRegionsErr) Unwrap() error {
+	return e.Err
+}
+
+// regionCommentOpeners and regionCommentClosers are the comment delimiters
+// that may surround a region marker on its line.
+var (
+	regionCommentOpeners = []string{"//", "#", "--", ";", "%", "/*", "<!--"}
+	regionCommentClosers = []string{"*/", "-->"}
+)
+
+// regionMarker returns the name of the region begun or ended by the line, if
+// the line consists solely of marker and a region name, optionally within a
+// comment.
+func regionMarker(line []byte, marker string) (string, bool) {
+	s := strings.TrimSpace(string(line))
+	for _, open := range regionCommentOpeners {
+		if strings.HasPrefix(s, open) {
+			s = s[len(open):]
+			break
+		}
+	}
+	for _, cl := range regionCommentClosers {
+		if strings.HasSuffix(s, cl) {
+			s = s[:len(s)-len(cl)]
+			break
+		}
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 2 || fields[0] != marker {
+		return "", false
+	}
+	return fields[1], true
+}
+
+// lines splits data into lines, each retaining its trailing newline.
+func lines(data []byte) [][]byte {
+	var ls [][]byte
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			ls = append(ls, data)
+			break
+		}
+		ls = append(ls, data[:i+1])
+		data = data[i+1:]
+	}
+	return ls
+}
+
+// regionSpan is the location of a region within a slice of lines. begin and end
+// are the indices of the marker lines.
+type regionSpan struct {
+	name       string
+	begin, end int
+}
+
+// parseRegions finds all regions in the provided lines, in order.
+func parseRegions(ls [][]byte) ([]regionSpan, error) {
+	var spans []regionSpan
+	seen := make(map[string]bool)
+	open := -1
+	for i, l := range ls {
+		if name, has := regionMarker(l, RegionBeginMarker); has {
+			if open >= 0 {
+				return nil, fmt.Errorf("line %d: region %q begins within region %q", i+1, name, spans[open].name)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("line %d: duplicate region %q", i+1, name)
+			}
+			seen[name] = true
+			open = len(spans)
+			spans = append(spans, regionSpan{name: name, begin: i})
+		} else if name, has := regionMarker(l, RegionEndMarker); has {
+			if open < 0 || spans[open].name != name {
+				return nil, fmt.Errorf("line %d: end of region %q, which was not begun", i+1, name)
+			}
+			spans[open].end = i
+			open = -1
+		}
+	}
+	if open >= 0 {
+		return nil, fmt.Errorf("region %q is never ended", spans[open].name)
+	}
+	return spans, nil
+}
+
+// stripRegions returns data with the contents of all regions removed, leaving
+// only their markers. data is returned unchanged if it contains no regions, or
+// its regions are invalid.
+func stripRegions(data []byte) []byte {
+	if !bytes.Contains(data, []byte(RegionBeginMarker)) {
+		return data
+	}
+	ls := lines(data)
+	spans, err := parseRegions(ls)
+	if err != nil || len(spans) == 0 {
+		return data
+	}
+
+	var buf bytes.Buffer
+	var last int
+	for _, span := range spans {
+		for _, l := range ls[last : span.begin+1] {
+			buf.Write(l)
+		}
+		last = span.end
+	}
+	for _, l := range ls[last:] {
+		buf.Write(l)
+	}
+	return buf.Bytes()
+}
+
+// mergeRegions merges the regions of existing into the Data of the File, if
+// the File has regions enabled, and otherwise returns its Data unchanged.
+func (f File) mergeRegions(existing []byte) ([]byte, []string, error) {
+	if !f.Regions {
+		return f.Data, nil, nil
+	}
+	return mergeRegions(f.Data, existing)
+}
+
+// mergeRegions returns the generated contents of a file, with the contents of
+// each region replaced by that of the same region in the existing contents
+// of the file. The names of regions in the existing contents that are absent
+// from the generated contents are also returned.
+//
+// generated is returned unchanged if neither contains any regions.
+func mergeRegions(generated, existing []byte) ([]byte, []string, error) {
+	if !bytes.Contains(generated, []byte(RegionBeginMarker)) && !bytes.Contains(existing, []byte(RegionBeginMarker)) {
+		return generated, nil, nil
+	}
+
+	gl := lines(generated)
+	gspans, err := parseRegions(gl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid regions in generated file: %w", err)
+	}
+	el := lines(existing)
+	espans, err := parseRegions(el)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid regions in existing file: %w", err)
+	}
+
+	ebyname := make(map[string]regionSpan, len(espans))
+	for _, span := range espans {
+		ebyname[span.name] = span
+	}
+
+	var buf bytes.Buffer
+	var last int
+	generatedNames := make(map[string]bool, len(gspans))
+	for _, span := range gspans {
+		generatedNames[span.name] = true
+		espan, has := ebyname[span.name]
+		if !has {
+			continue
+		}
+		for _, l := range gl[last : span.begin+1] {
+			buf.Write(l)
+		}
+		for _, l := range el[espan.begin+1 : espan.end] {
+			buf.Write(l)
+		}
+		last = span.end
+	}
+	for _, l := range gl[last:] {
+		buf.Write(l)
+	}
+
+	var orphans []string
+	for _, span := range espans {
+		if !generatedNames[span.name] {
+			orphans = append(orphans, span.name)
+		}
+	}
+	return buf.Bytes(), orphans, nil
+}
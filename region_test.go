@@ -0,0 +1,282 @@
+package codejen
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegionMarker(t *testing.T) {
+	tt := []struct {
+		line string
+		name string
+		ok   bool
+	}{
+		{line: "// codejen:region foo\n", name: "foo", ok: true},
+		{line: "\t# codejen:region foo", name: "foo", ok: true},
+		{line: "-- codejen:region foo", name: "foo", ok: true},
+		{line: "/* codejen:region foo */", name: "foo", ok: true},
+		{line: "<!-- codejen:region foo -->", name: "foo", ok: true},
+		{line: "codejen:region foo", name: "foo", ok: true},
+		{line: "//codejen:region foo", name: "foo", ok: true},
+		{line: "// codejen:region", ok: false},
+		{line: "// codejen:region foo bar", ok: false},
+		{line: "// codejen:endregion foo", ok: false},
+		{line: "// Mark regions with codejen:region and codejen:endregion.", ok: false},
+		{line: `fmt.Println("codejen:region foo")`, ok: false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.line, func(t *testing.T) {
+			name, ok := regionMarker([]byte(tc.line), RegionBeginMarker)
+			if ok != tc.ok || name != tc.name {
+				t.Errorf("got (%q, %t), want (%q, %t)", name, ok, tc.name, tc.ok)
+			}
+		})
+	}
+}
+
+func TestMergeRegions(t *testing.T) {
+	tt := []struct {
+		name      string
+		generated string
+		existing  string
+		want      string
+		orphans   []string
+		wantErr   bool
+	}{
+		{
+			name:      "no regions",
+			generated: "a\nb\n",
+			existing:  "c\n",
+			want:      "a\nb\n",
+		},
+		{
+			name:      "new file keeps default contents",
+			generated: "a\n// codejen:region r\ndefault\n// codejen:endregion r\nb\n",
+			existing:  "",
+			want:      "a\n// codejen:region r\ndefault\n// codejen:endregion r\nb\n",
+		},
+		{
+			name:      "preserves region contents",
+			generated: "a2\n// codejen:region r\ndefault\n// codejen:endregion r\nb2\n",
+			existing:  "a\n// codejen:region r\nhuman\nwritten\n// codejen:endregion r\nb\n",
+			want:      "a2\n// codejen:region r\nhuman\nwritten\n// codejen:endregion r\nb2\n",
+		},
+		{
+			name:      "preserves empty region",
+			generated: "// codejen:region r\ndefault\n// codejen:endregion r\n",
+			existing:  "// codejen:region r\n// codejen:endregion r\n",
+			want:      "// codejen:region r\n// codejen:endregion r\n",
+		},
+		{
+			name:      "multiple regions matched by name",
+			generated: "# codejen:region one\n1\n# codejen:endregion one\nx\n# codejen:region two\n2\n# codejen:endregion two\n",
+			existing:  "# codejen:region two\nTWO\n# codejen:endregion two\n# codejen:region one\nONE\n# codejen:endregion one\n",
+			want:      "# codejen:region one\nONE\n# codejen:endregion one\nx\n# codejen:region two\nTWO\n# codejen:endregion two\n",
+		},
+		{
+			name:      "region no longer generated",
+			generated: "a\n",
+			existing:  "a\n// codejen:region gone\nlost\n// codejen:endregion gone\n",
+			want:      "a\n",
+			orphans:   []string{"gone"},
+		},
+		{
+			name:      "markers in prose",
+			generated: "// Use codejen:region and codejen:endregion to protect code.\nx\n",
+			existing:  "// Use codejen:region and codejen:endregion to protect code.\ny\n",
+			want:      "// Use codejen:region and codejen:endregion to protect code.\nx\n",
+		},
+		{
+			name:      "nested regions",
+			generated: "// codejen:region a\n// codejen:region b\n// codejen:endregion b\n// codejen:endregion a\n",
+			wantErr:   true,
+		},
+		{
+			name:      "unended region in existing file",
+			generated: "// codejen:region a\n// codejen:endregion a\n",
+			existing:  "// codejen:region a\n",
+			wantErr:   true,
+		},
+		{
+			name:      "duplicate region",
+			generated: "// codejen:region a\n// codejen:endregion a\n// codejen:region a\n// codejen:endregion a\n",
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, orphans, err := mergeRegions([]byte(tc.generated), []byte(tc.existing))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("unexpected merge result (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.orphans, orphans); diff != "" {
+				t.Errorf("unexpected orphaned regions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStripRegions(t *testing.T) {
+	tt := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "no regions",
+			data: "a\nb\n",
+			want: "a\nb\n",
+		},
+		{
+			name: "strips contents",
+			data: "a\n// codejen:region r\nhuman\n// codejen:endregion r\nb\n",
+			want: "a\n// codejen:region r\n// codejen:endregion r\nb\n",
+		},
+		{
+			name: "invalid regions unchanged",
+			data: "a\n// codejen:region r\nhuman\n",
+			want: "a\n// codejen:region r\nhuman\n",
+		},
+		{
+			name: "markers in prose unchanged",
+			data: "// see codejen:region docs\nx\n// codejen:endregion too\n",
+			want: "// see codejen:region docs\nx\n// codejen:endregion too\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, string(stripRegions([]byte(tc.data)))); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteRegions(t *testing.T) {
+	const (
+		generated = "package x\n\n// codejen:region body\n// TODO\n// codejen:endregion body\n"
+		edited    = "package x\n\n// codejen:region body\nfunc F() {}\n// codejen:endregion body\n"
+	)
+
+	tt := []struct {
+		name    string
+		regions bool
+		want    string
+	}{
+		{name: "enabled", regions: true, want: edited},
+		{name: "disabled", regions: false, want: generated},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			jfs := NewFS()
+			err := jfs.Add(File{
+				RelativePath: "x.go",
+				Data:         []byte(generated),
+				From:         []NamedJenny{testJenny("X")},
+				Regions:      tc.regions,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			mt := memTarget(t, map[string]string{"out/x.go": edited})
+
+			if _, err := jfs.Write(context.Background(), "out", WithTarget(mt)); err != nil {
+				t.Fatal(err)
+			}
+			b, err := fs.ReadFile(mt, "out/x.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, string(b)); diff != "" {
+				t.Errorf("unexpected contents after write (-want +got):\n%s", diff)
+			}
+			if _, err := jfs.Verify(context.Background(), "out", WithTarget(mt)); err != nil {
+				t.Errorf("verify after write: %s", err)
+			}
+		})
+	}
+
+	t.Run("orphaned region", func(t *testing.T) {
+		jfs := NewFS()
+		err := jfs.Add(File{
+			RelativePath: "x.go",
+			Data:         []byte("package x\n"),
+			From:         []NamedJenny{testJenny("X")},
+			Regions:      true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt := memTarget(t, map[string]string{"out/x.go": edited})
+
+		_, err = jfs.Verify(context.Background(), "out", WithTarget(mt))
+		var oerr *OrphanedRegionErr
+		if !errors.As(err, &oerr) {
+			t.Fatalf("expected OrphanedRegionErr, got %v", err)
+		}
+		if oerr.Region != "body" {
+			t.Errorf("got orphaned region %q, want %q", oerr.Region, "body")
+		}
+	})
+
+	t.Run("invalid regions on disk", func(t *testing.T) {
+		jfs := NewFS()
+		err := jfs.Add(
+			File{
+				RelativePath: "x.go",
+				Data:         []byte(generated),
+				From:         []NamedJenny{testJenny("X")},
+				Regions:      true,
+			},
+			File{
+				RelativePath: "y.go",
+				Data:         []byte("package y\n"),
+				From:         []NamedJenny{testJenny("Y")},
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt := memTarget(t, map[string]string{
+			"out/x.go": "package x\n\n// codejen:region body\nfunc F() {}\n",
+			"out/y.go": "package y\n",
+		})
+
+		report, err := jfs.Verify(context.Background(), "out", WithTarget(mt))
+		var rerr *InvalidRegionsErr
+		if !errors.As(err, &rerr) {
+			t.Fatalf("verify: expected InvalidRegionsErr, got %v", err)
+		}
+		if rerr.RelativePath != "x.go" {
+			t.Errorf("verify: got invalid regions in %s, want x.go", rerr.RelativePath)
+		}
+		if diff := cmp.Diff([]string{"x.go"}, report.Paths(StateDiffers)); diff != "" {
+			t.Errorf("verify: unexpected differing files (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"y.go"}, report.Paths(StateUnchanged)); diff != "" {
+			t.Errorf("verify: unexpected unchanged files (-want +got):\n%s", diff)
+		}
+
+		_, err = jfs.Write(context.Background(), "out", WithTarget(mt))
+		if !errors.As(err, &rerr) {
+			t.Fatalf("write: expected InvalidRegionsErr, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package codejen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileState describes the outcome for a single file of a call to [FS.Write],
+// [FS.Verify] or [FS.Prune].
+type FileState string
+
+const (
+	// StateCreated indicates Write created a file that did not previously exist.
+	StateCreated FileState = "created"
+
+	// StateUpdated indicates Write replaced the contents of an existing file.
+	StateUpdated FileState = "updated"
+
+	// StateUnchanged indicates the file on disk already had the generated
+	// contents. Write did not write the file, and Verify found no difference.
+	StateUnchanged FileState = "unchanged"
+
+	// StateMissing indicates Verify found that a generated file does not exist.
+	StateMissing FileState = "missing"
+
+	// StateDiffers indicates Verify found that the contents of a file on disk
+	// differ from the generated contents.
+	StateDiffers FileState = "differs"
+
+	// StateOrphaned indicates Verify found a file recorded in the manifest of a
+	// previous Write that is no longer generated, but still exists.
+	StateOrphaned FileState = "orphaned"
+
+	// StatePruned indicates Prune removed an orphaned file.
+	StatePruned FileState = "pruned"
+
+	// StateKept indicates an existing file was left as is due to its
+	// [File.Policy]. Write did not write the file, and Verify did not check its
+	// contents.
+	StateKept FileState = "kept"
+)
+
+// stateOrder is the order in which states are summarized by [WriteReport.String].
+var stateOrder = []FileState{
+	StateCreated,
+	StateUpdated,
+	StateUnchanged,
+	StateKept,
+	StateMissing,
+	StateDiffers,
+	StateOrphaned,
+	StatePruned,
+}
+
+// ReportEntry describes what was done with a single file.
+type ReportEntry struct {
+	// Path is the path of the file, relative to the prefix.
+	Path string `json:"path"`
+
+	// State is the outcome for the file.
+	State FileState `json:"state"`
+
+	// Bytes is the size of the generated contents of the file.
+	Bytes int `json:"bytes"`
+
+	// From is the jenny stack responsible for producing the file, as returned
+	// from [File.FromString].
+	From string `json:"from"`
+
+	// Duration is the time spent on the file's IO.
+	Duration time.Duration `json:"duration"`
+}
+
+// WriteReport describes what a call to [FS.Write], [FS.Verify] or [FS.Prune]
+// did. It is suitable for serialization as JSON.
+type WriteReport struct {
+	// Files contains an entry per processed file, sorted by path.
+	Files []ReportEntry `json:"files"`
+
+	// Duration is the total time taken.
+	Duration time.Duration `json:"duration"`
+}
+
+// Count returns the number of files in the report having the provided state.
+func (r *WriteReport) Count(state FileState) int {
+	var n int
+	for _, e := range r.Files {
+		if e.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Paths returns the paths of all files in the report having the provided state.
+func (r *WriteReport) Paths(state FileState) []string {
+	var paths []string
+	for _, e := range r.Files {
+		if e.State == state {
+			paths = append(paths, e.Path)
+		}
+	}
+	return paths
+}
+
+// String summarizes the report, e.g. "12 created, 3 updated, 400 unchanged".
+func (r *WriteReport) String() string {
+	var parts []string
+	for _, state := range stateOrder {
+		if n := r.Count(state); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, state))
+		}
+	}
+	if len(parts) == 0 {
+		return "no files"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// newWriteReport builds a WriteReport from the provided entries. Entries with
+// an empty State were not processed, and are omitted.
+func newWriteReport(start time.Time, entries []ReportEntry) *WriteReport {
+	r := &WriteReport{
+		Files:    make([]ReportEntry, 0, len(entries)),
+		Duration: time.Since(start),
+	}
+	for _, e := range entries {
+		if e.State != "" {
+			r.Files = append(r.Files, e)
+		}
+	}
+	sort.Slice(r.Files, func(i, j int) bool {
+		return r.Files[i].Path < r.Files[j].Path
+	})
+	return r
+}
+
+// entry creates a ReportEntry for the file in the provided state.
+func (f File) entry(state FileState, start time.Time) ReportEntry {
+	return ReportEntry{
+		Path:     f.RelativePath,
+		State:    state,
+		Bytes:    len(f.Data),
+		From:     f.FromString(),
+		Duration: time.Since(start),
+	}
+}
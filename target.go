@@ -0,0 +1,395 @@
+package codejen
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target is a writable filesystem to which [FS.Write] writes files, and against
+// which [FS.Verify] compares them. The methods of Target behave like their
+// counterparts in package os.
+//
+// Names passed to Target methods are the prefix passed to Write or Verify,
+// joined with [File.RelativePath] using [filepath.Join].
+//
+// Target implementations must be safe for concurrent use.
+type Target interface {
+	// Stat returns a FileInfo describing the named file, following symlinks.
+	Stat(name string) (fs.FileInfo, error)
+
+	// Lstat returns a FileInfo describing the named file, without following
+	// symlinks.
+	Lstat(name string) (fs.FileInfo, error)
+
+	// ReadFile reads the named file and returns its contents.
+	ReadFile(name string) ([]byte, error)
+
+	// WriteFile writes data to the named file, creating it with permissions
+	// perm if necessary.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+
+	// MkdirAll creates a directory named path, along with any necessary
+	// parents, with permissions perm.
+	MkdirAll(path string, perm fs.FileMode) error
+
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+
+	// Rename moves oldpath to newpath, replacing newpath if it already exists.
+	Rename(oldpath, newpath string) error
+
+	// Chmod changes the permissions of the named file.
+	Chmod(name string, mode fs.FileMode) error
+
+	// Sync commits the contents of the named file to stable storage.
+	Sync(name string) error
+}
+
+// OSTarget is a [Target] backed by the real filesystem, through package os. It
+// is the default Target of [FS.Write] and [FS.Verify].
+type OSTarget struct{}
+
+var _ Target = OSTarget{}
+
+func (OSTarget) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (OSTarget) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (OSTarget) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name) //nolint:gosec
+}
+
+func (OSTarget) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+func (OSTarget) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (OSTarget) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (OSTarget) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (OSTarget) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+func (OSTarget) Sync(name string) error {
+	f, err := os.Open(name) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// targetPath converts a name passed to a Target method into a path valid for
+// use with [io/fs].
+func targetPath(op, name string) (string, error) {
+	p := path.Clean(filepath.ToSlash(name))
+	if !fs.ValidPath(p) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return p, nil
+}
+
+// ReadOnlyTarget returns a [Target] that reads from the provided [io/fs.FS],
+// such as an [embed.FS] or a snapshot of a git tree. It is intended for use
+// with [FS.Verify]; all methods that modify the Target fail.
+//
+// As io/fs paths are always relative, the prefix passed to Verify must be
+// relative, or empty.
+func ReadOnlyTarget(fsys fs.FS) Target {
+	return roTarget{fsys: fsys}
+}
+
+type roTarget struct {
+	fsys fs.FS
+}
+
+func (t roTarget) Stat(name string) (fs.FileInfo, error) {
+	p, err := targetPath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(t.fsys, p)
+}
+
+func (t roTarget) Lstat(name string) (fs.FileInfo, error) {
+	// io/fs has no notion of symlinks
+	return t.Stat(name)
+}
+
+func (t roTarget) ReadFile(name string) ([]byte, error) {
+	p, err := targetPath("read", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(t.fsys, p)
+}
+
+func (roTarget) WriteFile(name string, _ []byte, _ fs.FileMode) error {
+	return &fs.PathError{Op: "write", Path: name, Err: fs.ErrPermission}
+}
+
+func (roTarget) MkdirAll(path string, _ fs.FileMode) error {
+	return &fs.PathError{Op: "mkdir", Path: path, Err: fs.ErrPermission}
+}
+
+func (roTarget) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+}
+
+func (roTarget) Rename(oldpath, _ string) error {
+	return &fs.PathError{Op: "rename", Path: oldpath, Err: fs.ErrPermission}
+}
+
+func (roTarget) Chmod(name string, _ fs.FileMode) error {
+	return &fs.PathError{Op: "chmod", Path: name, Err: fs.ErrPermission}
+}
+
+func (roTarget) Sync(name string) error {
+	return &fs.PathError{Op: "sync", Path: name, Err: fs.ErrPermission}
+}
+
+// MemTarget is an in-memory [Target]. It also implements [io/fs.FS], allowing
+// inspection of what was written to it. It is intended for testing generators
+// without touching disk.
+//
+// As io/fs paths are always relative, the prefix passed to [FS.Write] or
+// [FS.Verify] must be relative, or empty.
+type MemTarget struct {
+	mu    sync.RWMutex
+	files mapFS
+}
+
+var (
+	_ Target = &MemTarget{}
+	_ fs.FS  = &MemTarget{}
+)
+
+// NewMemTarget creates a new, empty MemTarget.
+func NewMemTarget() *MemTarget {
+	return &MemTarget{
+		files: make(mapFS),
+	}
+}
+
+// Open opens the named file for reading, per [io/fs.FS].
+func (t *MemTarget) Open(name string) (fs.File, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	// entries in the map are never mutated, only replaced, so the returned
+	// file remains safe to read after the lock is released.
+	return t.files.Open(name)
+}
+
+func (t *MemTarget) Stat(name string) (fs.FileInfo, error) {
+	p, err := targetPath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.files.Stat(p)
+}
+
+func (t *MemTarget) Lstat(name string) (fs.FileInfo, error) {
+	return t.Stat(name)
+}
+
+func (t *MemTarget) ReadFile(name string) ([]byte, error) {
+	p, err := targetPath("read", name)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.files.ReadFile(p)
+}
+
+func (t *MemTarget) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	p, err := targetPath("write", name)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.checkDir("write", p); err != nil {
+		return err
+	}
+	mode := perm.Perm()
+	if f, has := t.files[p]; has {
+		if f.Mode.IsDir() {
+			return &fs.PathError{Op: "write", Path: name, Err: fmt.Errorf("is a directory")}
+		}
+		// Like os.WriteFile, permissions of existing files are retained.
+		mode = f.Mode
+	} else if t.hasChildren(p) {
+		return &fs.PathError{Op: "write", Path: name, Err: fmt.Errorf("is a directory")}
+	}
+	t.files[p] = &mapFile{
+		Data:    append([]byte(nil), data...),
+		Mode:    mode,
+		ModTime: time.Now(),
+	}
+	return nil
+}
+
+func (t *MemTarget) MkdirAll(name string, perm fs.FileMode) error {
+	p, err := targetPath("mkdir", name)
+	if err != nil {
+		return err
+	}
+	if p == "." {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.checkParents("mkdir", p); err != nil {
+		return err
+	}
+	if f, has := t.files[p]; has && !f.Mode.IsDir() {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fmt.Errorf("not a directory")}
+	}
+	for dir := p; dir != "."; dir = path.Dir(dir) {
+		if _, has := t.files[dir]; !has {
+			t.files[dir] = &mapFile{
+				Mode:    fs.ModeDir | perm.Perm(),
+				ModTime: time.Now(),
+			}
+		}
+	}
+	return nil
+}
+
+func (t *MemTarget) Remove(name string) error {
+	p, err := targetPath("remove", name)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.hasChildren(p) {
+		return &fs.PathError{Op: "remove", Path: name, Err: fmt.Errorf("directory not empty")}
+	}
+	if _, has := t.files[p]; !has {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(t.files, p)
+	return nil
+}
+
+func (t *MemTarget) Rename(oldpath, newpath string) error {
+	op, err := targetPath("rename", oldpath)
+	if err != nil {
+		return err
+	}
+	np, err := targetPath("rename", newpath)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, has := t.files[op]
+	if !has {
+		return &fs.PathError{Op: "rename", Path: oldpath, Err: fs.ErrNotExist}
+	}
+	if f.Mode.IsDir() || t.hasChildren(op) {
+		return &fs.PathError{Op: "rename", Path: oldpath, Err: fmt.Errorf("renaming directories is not supported")}
+	}
+	if nf, has := t.files[np]; (has && nf.Mode.IsDir()) || t.hasChildren(np) {
+		return &fs.PathError{Op: "rename", Path: newpath, Err: fmt.Errorf("is a directory")}
+	}
+	if err := t.checkDir("rename", np); err != nil {
+		return err
+	}
+	delete(t.files, op)
+	t.files[np] = f
+	return nil
+}
+
+func (t *MemTarget) Chmod(name string, mode fs.FileMode) error {
+	p, err := targetPath("chmod", name)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, has := t.files[p]
+	if !has {
+		return &fs.PathError{Op: "chmod", Path: name, Err: fs.ErrNotExist}
+	}
+	nf := *f
+	nf.Mode = f.Mode.Type() | mode.Perm()
+	t.files[p] = &nf
+	return nil
+}
+
+func (t *MemTarget) Sync(name string) error {
+	_, err := t.Stat(name)
+	return err
+}
+
+// checkParents returns an error if any parent of p is a file. Must be called
+// with the lock held.
+func (t *MemTarget) checkParents(op, p string) error {
+	for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+		if f, has := t.files[dir]; has && !f.Mode.IsDir() {
+			return &fs.PathError{Op: op, Path: p, Err: fmt.Errorf("%s is not a directory", dir)}
+		}
+	}
+	return nil
+}
+
+// checkDir returns an error if the parent directory of p does not exist, or any
+// parent of p is a file. Must be called with the lock held.
+func (t *MemTarget) checkDir(op, p string) error {
+	if err := t.checkParents(op, p); err != nil {
+		return err
+	}
+	if dir := path.Dir(p); dir != "." {
+		if _, has := t.files[dir]; !has {
+			return &fs.PathError{Op: op, Path: p, Err: fs.ErrNotExist}
+		}
+	}
+	return nil
+}
+
+// hasChildren reports whether any entry exists beneath p. Must be called with
+// the lock held.
+func (t *MemTarget) hasChildren(p string) bool {
+	if p == "." {
+		return len(t.files) > 0
+	}
+	prefix := p + "/"
+	for name := range t.files {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
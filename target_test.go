@@ -0,0 +1,248 @@
+package codejen
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// snapshot returns the contents of every file in mt by path, with directories
+// mapped to "/".
+func snapshot(t *testing.T, mt *MemTarget) map[string]string {
+	t.Helper()
+	m := make(map[string]string)
+	err := fs.WalkDir(mt, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == "." {
+			return err
+		}
+		if d.IsDir() {
+			m[p] = "/"
+			return nil
+		}
+		b, err := fs.ReadFile(mt, p)
+		m[p] = string(b)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("walking MemTarget: %s", err)
+	}
+	return m
+}
+
+// testJenny is a NamedJenny for use in constructing Files.
+type testJenny string
+
+func (j testJenny) JennyName() string {
+	return string(j)
+}
+
+// memTarget creates a MemTarget containing the provided files, by path.
+func memTarget(t *testing.T, files map[string]string) *MemTarget {
+	t.Helper()
+	mt := NewMemTarget()
+	for p, data := range files {
+		if err := mt.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := mt.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mt
+}
+
+func TestMemTargetRoundTrip(t *testing.T) {
+	files := []File{
+		{RelativePath: "a.txt", Data: []byte("a\n"), From: []NamedJenny{testJenny("A")}},
+		{RelativePath: "sub/b.sh", Data: []byte("#!/bin/sh\n"), From: []NamedJenny{testJenny("B")}, Mode: 0o755},
+		{RelativePath: "sub/deeper/c.txt", Data: []byte("c\n"), From: []NamedJenny{testJenny("C")}},
+	}
+
+	tt := map[string][]FSOption{
+		"direct":   nil,
+		"atomic":   {WithAtomic()},
+		"manifest": {WithManifest("")},
+	}
+	for name, opts := range tt {
+		t.Run(name, func(t *testing.T) {
+			jfs := NewFS()
+			if err := jfs.Add(files...); err != nil {
+				t.Fatal(err)
+			}
+			mt := NewMemTarget()
+			wopts := append([]FSOption{WithTarget(mt)}, opts...)
+
+			if _, err := jfs.Write(context.Background(), "out", wopts...); err != nil {
+				t.Fatalf("write: %s", err)
+			}
+			for _, f := range files {
+				p := filepath.Join("out", f.RelativePath)
+				b, err := fs.ReadFile(mt, p)
+				if err != nil {
+					t.Fatalf("reading %s: %s", p, err)
+				}
+				if string(b) != string(f.Data) {
+					t.Errorf("%s: got %q, want %q", p, b, f.Data)
+				}
+				if f.Mode != 0 {
+					fi, err := mt.Stat(p)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if fi.Mode().Perm() != f.Mode {
+						t.Errorf("%s: got mode %s, want %s", p, fi.Mode().Perm(), f.Mode)
+					}
+				}
+			}
+			if _, err := jfs.Verify(context.Background(), "out", wopts...); err != nil {
+				t.Fatalf("verify after write: %s", err)
+			}
+
+			if err := mt.WriteFile(filepath.Join("out", "a.txt"), []byte("edited\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := jfs.Verify(context.Background(), "out", wopts...)
+			var cerr *ContentsDifferErr
+			if !errors.As(err, &cerr) {
+				t.Fatalf("verify after edit: expected ContentsDifferErr, got %v", err)
+			}
+			if cerr.RelativePath != "a.txt" {
+				t.Errorf("verify after edit: got difference in %s, want a.txt", cerr.RelativePath)
+			}
+		})
+	}
+}
+
+func TestMemTargetOps(t *testing.T) {
+	tt := []struct {
+		name  string
+		files map[string]string
+		op    func(mt *MemTarget) error
+		fail  bool
+		is    error
+		want  map[string]string
+	}{
+		{
+			name:  "rename file",
+			files: map[string]string{"d/a": "a"},
+			op:    func(mt *MemTarget) error { return mt.Rename("d/a", "d/b") },
+			want:  map[string]string{"d": "/", "d/b": "a"},
+		},
+		{
+			name:  "rename replaces existing file",
+			files: map[string]string{"d/a": "a", "d/b": "b"},
+			op:    func(mt *MemTarget) error { return mt.Rename("d/a", "d/b") },
+			want:  map[string]string{"d": "/", "d/b": "a"},
+		},
+		{
+			name:  "rename missing file",
+			files: map[string]string{"d/a": "a"},
+			op:    func(mt *MemTarget) error { return mt.Rename("d/x", "d/b") },
+			fail:  true,
+			is:    fs.ErrNotExist,
+			want:  map[string]string{"d": "/", "d/a": "a"},
+		},
+		{
+			name:  "rename directory",
+			files: map[string]string{"d/a": "a"},
+			op:    func(mt *MemTarget) error { return mt.Rename("d", "e") },
+			fail:  true,
+			want:  map[string]string{"d": "/", "d/a": "a"},
+		},
+		{
+			name:  "rename onto directory",
+			files: map[string]string{"d/a": "a", "e/b": "b"},
+			op:    func(mt *MemTarget) error { return mt.Rename("d/a", "e") },
+			fail:  true,
+			want:  map[string]string{"d": "/", "d/a": "a", "e": "/", "e/b": "b"},
+		},
+		{
+			name:  "rename beneath file",
+			files: map[string]string{"d/a": "a", "d/b": "b"},
+			op:    func(mt *MemTarget) error { return mt.Rename("d/a", "d/b/c") },
+			fail:  true,
+			want:  map[string]string{"d": "/", "d/a": "a", "d/b": "b"},
+		},
+		{
+			name:  "rename into missing directory",
+			files: map[string]string{"d/a": "a"},
+			op:    func(mt *MemTarget) error { return mt.Rename("d/a", "e/a") },
+			fail:  true,
+			is:    fs.ErrNotExist,
+			want:  map[string]string{"d": "/", "d/a": "a"},
+		},
+		{
+			name:  "write into missing directory",
+			files: map[string]string{"d/a": "a"},
+			op:    func(mt *MemTarget) error { return mt.WriteFile("d/e/b", []byte("b"), 0o644) },
+			fail:  true,
+			is:    fs.ErrNotExist,
+			want:  map[string]string{"d": "/", "d/a": "a"},
+		},
+		{
+			name:  "write after mkdir",
+			files: map[string]string{"d/a": "a"},
+			op: func(mt *MemTarget) error {
+				if err := mt.MkdirAll("d/e", 0o755); err != nil {
+					return err
+				}
+				return mt.WriteFile("d/e/b", []byte("b"), 0o644)
+			},
+			want: map[string]string{"d": "/", "d/a": "a", "d/e": "/", "d/e/b": "b"},
+		},
+		{
+			name:  "remove file",
+			files: map[string]string{"d/a": "a", "d/b": "b"},
+			op:    func(mt *MemTarget) error { return mt.Remove("d/a") },
+			want:  map[string]string{"d": "/", "d/b": "b"},
+		},
+		{
+			name:  "remove empty directory",
+			files: map[string]string{"d/a": "a"},
+			op: func(mt *MemTarget) error {
+				if err := mt.Remove("d/a"); err != nil {
+					return err
+				}
+				return mt.Remove("d")
+			},
+			want: map[string]string{},
+		},
+		{
+			name:  "remove non-empty directory",
+			files: map[string]string{"d/a": "a"},
+			op:    func(mt *MemTarget) error { return mt.Remove("d") },
+			fail:  true,
+			want:  map[string]string{"d": "/", "d/a": "a"},
+		},
+		{
+			name:  "remove missing file",
+			files: map[string]string{"d/a": "a"},
+			op:    func(mt *MemTarget) error { return mt.Remove("d/x") },
+			fail:  true,
+			is:    fs.ErrNotExist,
+			want:  map[string]string{"d": "/", "d/a": "a"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := memTarget(t, tc.files)
+			err := tc.op(mt)
+			switch {
+			case !tc.fail && err != nil:
+				t.Errorf("unexpected error: %s", err)
+			case tc.fail && err == nil:
+				t.Errorf("expected error")
+			case tc.is != nil && !errors.Is(err, tc.is):
+				t.Errorf("expected error matching %v, got %v", tc.is, err)
+			}
+			if diff := cmp.Diff(tc.want, snapshot(t, mt)); diff != "" {
+				t.Errorf("unexpected contents (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package codejen
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// txn is a write transaction over a set of files. Files are first staged into
+// temporary files next to their targets, then committed by renaming them into
+// place. If staging or committing fails, the transaction is rolled back,
+// restoring the original state of the filesystem.
+//
+// Rollback is best effort - it is impossible to guarantee, as the filesystem
+// may fail while rolling back.
+type txn struct {
+	mu sync.Mutex
+
+	opts *fsOptions
+
+	// directories created while staging
+	dirs []string
+
+	staged []*stagedFile
+}
+
+type stagedFile struct {
+	// path is the target path of the file.
+	path string
+
+	// tmp is the path of the temporary file containing the new contents.
+	tmp string
+
+	// backup is the path to which the original file was moved during commit.
+	// Empty if there was no original file.
+	backup string
+
+	committed bool
+}
+
+// stage writes data to a temporary file next to path, creating any missing
+// parent directories. It is safe to call stage concurrently.
+func (tx *txn) stage(path string, data []byte, perm fs.FileMode) error {
+	dir := filepath.Dir(path)
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := tx.opts.target.Stat(d); err == nil || !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if len(missing) > 0 {
+		if err := tx.opts.target.MkdirAll(dir, tx.opts.dirMode); err != nil {
+			return fmt.Errorf("%s: failed to ensure parent directory exists: %w", path, err)
+		}
+		tx.mu.Lock()
+		tx.dirs = append(tx.dirs, missing...)
+		tx.mu.Unlock()
+	}
+
+	sf := &stagedFile{
+		path: path,
+		tmp:  stagingPath(path),
+	}
+	tx.mu.Lock()
+	tx.staged = append(tx.staged, sf)
+	tx.mu.Unlock()
+
+	err := tx.opts.writeFile(sf.tmp, data, perm)
+	if err == nil {
+		// permissions of new files are subject to umask
+		err = tx.opts.target.Chmod(sf.tmp, perm)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: error while writing staging file: %w", path, err)
+	}
+	return nil
+}
+
+// commit moves all staged files into place, rolling back if any step fails or
+// the context is cancelled. Backups of original files are left in place until
+// [txn.cleanup] is called.
+func (tx *txn) commit(ctx context.Context) error {
+	sort.Slice(tx.staged, func(i, j int) bool {
+		return tx.staged[i].path < tx.staged[j].path
+	})
+
+	for _, sf := range tx.staged {
+		if err := ctx.Err(); err != nil {
+			return tx.rollback(err)
+		}
+
+		if fi, err := tx.opts.target.Lstat(sf.path); err == nil {
+			if fi.IsDir() {
+				return tx.rollback(fmt.Errorf("%s: cannot overwrite directory with file", sf.path))
+			}
+			backup := sf.tmp + ".orig"
+			if err := tx.opts.target.Rename(sf.path, backup); err != nil {
+				return tx.rollback(fmt.Errorf("%s: failed to back up original file: %w", sf.path, err))
+			}
+			sf.backup = backup
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return tx.rollback(fmt.Errorf("%s: could not stat file: %w", sf.path, err))
+		}
+
+		if err := tx.opts.target.Rename(sf.tmp, sf.path); err != nil {
+			return tx.rollback(fmt.Errorf("%s: failed to move staging file into place: %w", sf.path, err))
+		}
+		sf.committed = true
+	}
+	return nil
+}
+
+// cleanup removes the backups of original files made by a successful commit.
+// Failure to remove a backup leaves a stray file behind, but does not affect
+// the committed files.
+func (tx *txn) cleanup() error {
+	var result *multierror.Error
+	for _, sf := range tx.staged {
+		if sf.backup != "" {
+			if err := tx.opts.target.Remove(sf.backup); err != nil {
+				result = multierror.Append(result, fmt.Errorf("%s: failed to remove backup of original file: %w", sf.path, err))
+			}
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// rollback undoes all changes made by the transaction. The provided error is
+// returned unchanged if rolling back succeeded, and otherwise along with the
+// errors encountered while rolling back.
+func (tx *txn) rollback(cause error) error {
+	var result *multierror.Error
+
+	for i := len(tx.staged) - 1; i >= 0; i-- {
+		sf := tx.staged[i]
+		switch {
+		case sf.committed && sf.backup != "":
+			if err := tx.opts.target.Rename(sf.backup, sf.path); err != nil {
+				result = multierror.Append(result, fmt.Errorf("%s: failed to restore original file from %s: %w", sf.path, sf.backup, err))
+			}
+		case sf.committed:
+			if err := tx.opts.target.Remove(sf.path); err != nil {
+				result = multierror.Append(result, fmt.Errorf("%s: failed to remove created file: %w", sf.path, err))
+			}
+		case sf.backup != "":
+			// Original was moved aside, but the staged file never made it into place.
+			if err := tx.opts.target.Rename(sf.backup, sf.path); err != nil {
+				result = multierror.Append(result, fmt.Errorf("%s: failed to restore original file from %s: %w", sf.path, sf.backup, err))
+			}
+			fallthrough
+		default:
+			if err := tx.opts.target.Remove(sf.tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+				result = multierror.Append(result, fmt.Errorf("%s: failed to remove staging file: %w", sf.path, err))
+			}
+		}
+	}
+
+	// Remove the deepest directories first. Concurrent staging may have recorded
+	// a directory more than once, and removal fails harmlessly on directories
+	// that are not empty or already gone.
+	sort.Slice(tx.dirs, func(i, j int) bool {
+		return len(tx.dirs[i]) > len(tx.dirs[j])
+	})
+	for _, dir := range tx.dirs {
+		_ = tx.opts.target.Remove(dir)
+	}
+
+	if result.ErrorOrNil() == nil {
+		return cause
+	}
+	return multierror.Append(cause, result.Errors...)
+}
+
+// stagingPath returns a unique path, next to path, at which to stage new
+// contents for path.
+func stagingPath(path string) string {
+	var suffix string
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err == nil {
+		suffix = hex.EncodeToString(b)
+	} else {
+		// crypto/rand does not fail in practice; fall back to something unlikely
+		// to collide
+		suffix = fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".codejen-"+suffix)
+}
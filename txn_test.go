@@ -0,0 +1,169 @@
+package codejen
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// failRenameTarget is a MemTarget on which the first rename from or to a
+// chosen path fails.
+type failRenameTarget struct {
+	*MemTarget
+	path   string
+	failed bool
+}
+
+var errInjected = errors.New("injected failure")
+
+func (t *failRenameTarget) Rename(oldpath, newpath string) error {
+	if !t.failed && (oldpath == t.path || newpath == t.path) {
+		t.failed = true
+		return &fs.PathError{Op: "rename", Path: oldpath, Err: errInjected}
+	}
+	return t.MemTarget.Rename(oldpath, newpath)
+}
+
+func TestTxnRollback(t *testing.T) {
+	existing := map[string]string{
+		"out/a.txt": "old a",
+		"out/m.txt": "old m",
+	}
+	files := []File{
+		{RelativePath: "a.txt", Data: []byte("new a"), From: []NamedJenny{testJenny("A")}},
+		{RelativePath: "b/c.txt", Data: []byte("new c"), From: []NamedJenny{testJenny("C")}},
+		{RelativePath: "m.txt", Data: []byte("new m"), From: []NamedJenny{testJenny("M")}},
+		{RelativePath: "z/y/x.txt", Data: []byte("new x"), From: []NamedJenny{testJenny("X")}},
+	}
+
+	tt := []struct {
+		name string
+		// fail is the path, relative to the prefix, for which a rename fails.
+		// Staged files are committed in path order.
+		fail string
+	}{
+		{name: "first file into place", fail: "a.txt"},
+		{name: "created file into place", fail: "b/c.txt"},
+		{name: "backup of original", fail: "m.txt"},
+		{name: "last file into place", fail: "z/y/x.txt"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			jfs := NewFS()
+			if err := jfs.Add(files...); err != nil {
+				t.Fatal(err)
+			}
+			mt := memTarget(t, existing)
+			before := snapshot(t, mt)
+			target := &failRenameTarget{MemTarget: mt, path: filepath.Join("out", tc.fail)}
+
+			_, err := jfs.Write(context.Background(), "out", WithTarget(target), WithAtomic())
+			if !errors.Is(err, errInjected) {
+				t.Fatalf("expected injected failure, got %v", err)
+			}
+			if !target.failed {
+				t.Fatal("rename never failed")
+			}
+			if diff := cmp.Diff(before, snapshot(t, mt)); diff != "" {
+				t.Errorf("target not restored after rollback (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// cancelRenameTarget is a MemTarget that cancels a context on the first rename.
+type cancelRenameTarget struct {
+	*MemTarget
+	cancel context.CancelFunc
+}
+
+func (t *cancelRenameTarget) Rename(oldpath, newpath string) error {
+	t.cancel()
+	return t.MemTarget.Rename(oldpath, newpath)
+}
+
+func TestTxnCancel(t *testing.T) {
+	tt := []struct {
+		name string
+		// target returns the Target to write to, given the MemTarget it wraps
+		// and a func to cancel the context of the Write.
+		target func(mt *MemTarget, cancel context.CancelFunc) Target
+	}{
+		{
+			name: "before staging",
+			target: func(mt *MemTarget, cancel context.CancelFunc) Target {
+				cancel()
+				return mt
+			},
+		},
+		{
+			name: "during commit",
+			target: func(mt *MemTarget, cancel context.CancelFunc) Target {
+				return &cancelRenameTarget{MemTarget: mt, cancel: cancel}
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			jfs := NewFS()
+			err := jfs.Add(
+				File{RelativePath: "a.txt", Data: []byte("new a"), From: []NamedJenny{testJenny("A")}},
+				File{RelativePath: "b/c.txt", Data: []byte("new c"), From: []NamedJenny{testJenny("C")}},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mt := memTarget(t, map[string]string{"out/a.txt": "old a"})
+			before := snapshot(t, mt)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, err = jfs.Write(ctx, "out", WithTarget(tc.target(mt, cancel)), WithAtomic())
+			// ctx.Err() must be returned as is, not wrapped.
+			if err != context.Canceled {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+			if diff := cmp.Diff(before, snapshot(t, mt)); diff != "" {
+				t.Errorf("target not restored after cancellation (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTxnCommit(t *testing.T) {
+	jfs := NewFS()
+	err := jfs.Add(
+		File{RelativePath: "a.txt", Data: []byte("new a"), From: []NamedJenny{testJenny("A")}},
+		File{RelativePath: "b/c.txt", Data: []byte("new c"), From: []NamedJenny{testJenny("C")}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt := memTarget(t, map[string]string{"out/a.txt": "old a"})
+
+	report, err := jfs.Write(context.Background(), "out", WithTarget(mt), WithAtomic())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"out":         "/",
+		"out/a.txt":   "new a",
+		"out/b":       "/",
+		"out/b/c.txt": "new c",
+	}
+	if diff := cmp.Diff(want, snapshot(t, mt)); diff != "" {
+		t.Errorf("unexpected contents after commit, staging files or backups left behind? (-want +got):\n%s", diff)
+	}
+	if got := report.Count(StateUpdated); got != 1 {
+		t.Errorf("expected 1 updated file, got %d", got)
+	}
+	if got := report.Count(StateCreated); got != 1 {
+		t.Errorf("expected 1 created file, got %d", got)
+	}
+}